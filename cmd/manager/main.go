@@ -6,6 +6,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 
@@ -15,6 +16,9 @@ import (
 
 	"github.com/bbrowning/crc-operator/pkg/apis"
 	"github.com/bbrowning/crc-operator/pkg/controller"
+	"github.com/bbrowning/crc-operator/pkg/controller/crccluster"
+	"github.com/bbrowning/crc-operator/pkg/export"
+	"github.com/bbrowning/crc-operator/pkg/webhook"
 	"github.com/bbrowning/crc-operator/version"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -29,6 +33,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	kubevirtv1 "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -42,6 +47,13 @@ var (
 	metricsPort         int32 = 8383
 	operatorMetricsPort int32 = 8686
 )
+
+// Change below variables to serve admission webhooks on a different
+// port or from a different certificate directory.
+var (
+	webhookPort    = 9443
+	webhookCertDir = "/tmp/k8s-webhook-server/serving-certs"
+)
 var log = logf.Log.WithName("cmd")
 
 func printVersion() {
@@ -72,6 +84,15 @@ func main() {
 	// uniform and structured logs.
 	logf.SetLogger(zap.Logger())
 
+	// The same binary serves the disks of CrcClusterExports
+	if pflag.Arg(0) == export.ServerCommand {
+		if err := export.Serve(); err != nil {
+			log.Error(err, "Export server failed")
+			os.Exit(1)
+		}
+		return
+	}
+
 	printVersion()
 
 	namespace, err := k8sutil.GetWatchNamespace()
@@ -99,6 +120,8 @@ func main() {
 	options := manager.Options{
 		Namespace:          namespace,
 		MetricsBindAddress: fmt.Sprintf("%s:%d", metricsHost, metricsPort),
+		Port:               webhookPort,
+		CertDir:            webhookCertDir,
 	}
 
 	// Add support for MultiNamespace set in WATCH_NAMESPACE (e.g ns1,ns2)
@@ -131,6 +154,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Add CDI scheme
+	if err := cdiv1.AddToScheme(mgr.GetScheme()); err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+
 	// Add OpenShift schemes
 	if err := routev1.AddToScheme(mgr.GetScheme()); err != nil {
 		log.Error(err, "")
@@ -147,6 +176,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Setup all Webhooks, but only if we have a serving certificate
+	// for them
+	if _, err := os.Stat(filepath.Join(webhookCertDir, "tls.crt")); err == nil {
+		if err := webhook.AddToManager(mgr); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+		crccluster.AdmissionWebhooksEnabled = true
+	} else {
+		log.Info("Skipping admission webhooks; no serving certificate found.", "CertDir", webhookCertDir)
+	}
+
 	// Add the Metrics Service
 	addMetrics(ctx, cfg)
 
@@ -36,6 +36,29 @@ type CrcBundleSpec struct {
 	// Kubeconfig is the base64 encoded initial kubeconfig to connect
 	// to this bundle
 	Kubeconfig string `json:"kubeconfig"`
+
+	// Overcommit overrides the CRC Operator's default overcommit
+	// settings for clusters using this bundle
+	Overcommit CrcOvercommitSpec `json:"overcommit,omitempty"`
+}
+
+// CrcOvercommitSpec defines how much of a cluster's CPU and memory
+// gets requested from the Node running it
+type CrcOvercommitSpec struct {
+	// CPURatio is how many guest CPUs share a single requested CPU
+	// on the Node, as a decimal number like "2" or "1.5". Clusters
+	// always request at least 2 CPUs.
+	CPURatio string `json:"cpuRatio,omitempty"`
+
+	// MemoryRatio is how many bytes of guest memory share a single
+	// requested byte on the Node, as a decimal number like "1" or
+	// "1.25".
+	MemoryRatio string `json:"memoryRatio,omitempty"`
+
+	// GuestOverhead indicates if the memory KubeVirt needs on top of
+	// the guest memory to run the VirtualMachine should be left out
+	// of the requests, and thus overcommitted.
+	GuestOverhead *bool `json:"guestOverhead,omitempty"`
 }
 
 // CrcBundleStatus defines the observed state of CrcBundle
@@ -1,6 +1,8 @@
 package v1alpha1
 
 import (
+	"fmt"
+
 	"github.com/operator-framework/operator-sdk/pkg/status"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,13 +20,20 @@ type CrcClusterSpec struct {
 	// TODO: Look for Hive / OpenShift Cluster Manager CRDs here
 	// instead of creating our own things
 
-	// CPU is the number of CPUs to allocate to the cluster
-	// +kubebuilder:default=4
-	CPU int `json:"cpu"`
+	// Size is the name of a size preset, like small, medium, or
+	// large, that chooses the CPU and Memory of this cluster. If not
+	// set, the CRC Operator's default size is used. Changing it
+	// replaces the CPU and Memory with those of the new size, unless
+	// they get changed along with it.
+	Size string `json:"size,omitempty"`
+
+	// CPU is the number of CPUs to allocate to the cluster. If set,
+	// this overrides the CPU of the chosen Size.
+	CPU int `json:"cpu,omitempty"`
 
-	// Memory is the amount of memory to allocate to the cluster
-	// +kubebuilder:default="16Gi"
-	Memory string `json:"memory"`
+	// Memory is the amount of memory to allocate to the cluster. If
+	// set, this overrides the memory of the chosen Size.
+	Memory string `json:"memory,omitempty"`
 
 	// PullSecret is your base64-encoded OpenShift pull secret
 	PullSecret string `json:"pullSecret"`
@@ -46,6 +55,23 @@ type CrcClusterSpec struct {
 	// will be chosen by the CRC Operator.
 	Storage CrcStorageSpec `json:"storage,omitempty"`
 
+	// Source is what to create this cluster's persistent volume from
+	// instead of the bundle. If not set, the bundle gets imported.
+	Source *CrcClusterSource `json:"source,omitempty"`
+
+	// ResetRequestedAt requests a factory reset of this cluster when
+	// set to a time after its last reset. The cluster's disks get
+	// recreated from the bundle and fully reconfigured, while it
+	// keeps its name, URLs, kubeadmin password, and admin client key.
+	// A reset cluster always starts over from its bundle, even if it
+	// was created from a source.
+	ResetRequestedAt *metav1.Time `json:"resetRequestedAt,omitempty"`
+
+	// EtcdBackup configures scheduled backups of this cluster's etcd
+	// to S3-compatible storage, and restoring one of them. If not
+	// set, etcd doesn't get backed up.
+	EtcdBackup *CrcEtcdBackupSpec `json:"etcdBackup,omitempty"`
+
 	// Stopped indicates if this cluster should be stopped or
 	// running. Stopped clusters with ephemeral storage will lose all
 	// when they're stopped and will come up as if they're a new
@@ -53,6 +79,40 @@ type CrcClusterSpec struct {
 	// storage will retain their data between stops and starts.
 	Stopped bool `json:"stopped,omitempty"`
 
+	// Paused indicates if this cluster's VirtualMachine should be
+	// frozen in memory. Unlike stopping, pausing keeps everything in
+	// the cluster, including ephemeral storage, and unpausing brings
+	// it back in seconds. A paused cluster keeps its memory and
+	// storage on the Node. Stopped takes precedence over this.
+	Paused bool `json:"paused,omitempty"`
+
+	// RunStrategy controls when the cluster's VirtualMachine gets
+	// started and restarted. Always keeps it running, restarting it
+	// whenever it stops. RerunOnFailure only restarts it if it
+	// fails. Manual leaves starting and stopping it up to the user,
+	// for example with virtctl. Halted keeps it stopped. Stopped
+	// takes precedence over this. Defaults to Always.
+	// +kubebuilder:validation:Enum=Always;Halted;RerunOnFailure;Manual
+	RunStrategy string `json:"runStrategy,omitempty"`
+
+	// ShutdownGracePeriod is how long to wait for OpenShift to shut
+	// down gracefully before the VirtualMachine of a cluster with
+	// persistent storage gets stopped. If not set, a default will be
+	// chosen by the CRC Operator.
+	ShutdownGracePeriod *metav1.Duration `json:"shutdownGracePeriod,omitempty"`
+
+	// DrainPolicy controls what happens to a cluster that can't be
+	// live migrated when the Node it runs on gets drained. Block
+	// keeps the drain from finishing until the cluster is stopped or
+	// deleted. Stop stops the cluster, which can be started again on
+	// another Node. Recreate restarts the cluster on another Node
+	// right away, losing everything in it unless its storage is
+	// persistent. Clusters with persistent ReadWriteMany storage get
+	// live migrated to another Node instead. If not set, a default
+	// will be chosen by the CRC Operator.
+	// +kubebuilder:validation:Enum=Block;Stop;Recreate
+	DrainPolicy CrcClusterDrainPolicy `json:"drainPolicy,omitempty"`
+
 	// EnableMonitoring indicates if this cluster should have
 	// OpenShift's cluster-monitoring-operator enabled by
 	// default. It's not suggested to enable this unless you assign at
@@ -64,6 +124,46 @@ type CrcClusterSpec struct {
 	// not actually delete the resources out of the
 	// openshift-monitoring namespace.
 	EnableMonitoring *bool `json:"enableMonitoring,omitempty"`
+
+	// Priority orders this cluster among other clusters waiting for
+	// capacity on the Nodes. Clusters with a higher priority are
+	// started first, and clusters with the same priority are started
+	// in the order they were created. Defaults to 0.
+	Priority int `json:"priority,omitempty"`
+
+	// Placement controls which Nodes this cluster's VirtualMachine
+	// may run on. Anything set here is combined with the defaults
+	// configured for the CRC Operator.
+	Placement CrcPlacementSpec `json:"placement,omitempty"`
+
+	// Performance tunes the cluster's VirtualMachine for workloads
+	// sensitive to CPU, memory, or disk latency, like etcd on busy
+	// Nodes. Changes take effect the next time the cluster is
+	// started.
+	Performance CrcPerformanceSpec `json:"performance,omitempty"`
+}
+
+// CrcPlacementSpec defines where the VirtualMachine of a CrcCluster
+// gets scheduled
+type CrcPlacementSpec struct {
+	// NodeSelector is a map of labels a Node must have to run this
+	// cluster. These are added to any default node selector labels.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations allow this cluster to run on Nodes with matching
+	// taints. These are added to any default tolerations.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity is the scheduling affinity of this cluster. If set,
+	// this replaces any default affinity. Unless it sets a
+	// podAntiAffinity, the CRC Operator adds one that prefers
+	// spreading clusters across Nodes.
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// PriorityClassName is the name of the PriorityClass of this
+	// cluster's VirtualMachine. If set, this replaces any default
+	// priority class.
+	PriorityClassName string `json:"priorityClassName,omitempty"`
 }
 
 // CrcStorageSpec defines the desired storage of CrcCluster
@@ -77,9 +177,207 @@ type CrcStorageSpec struct {
 	// +kubebuilder:default=false
 	Persistent bool `json:"persistent"`
 
-	// Size is the amount of persistent disk space to allocate to the
-	// cluster. This is ignored unless Persistent is set to true.
+	// Size is the amount of disk space to allocate to the cluster. If
+	// this is larger than the bundle's disk size for a cluster that
+	// isn't Persistent, /var gets relocated onto an emptyDisk of this
+	// size that's wiped when the cluster stops.
 	Size string `json:"size,omitempty"`
+
+	// StorageClassName is the StorageClass of the cluster's
+	// persistent volume. This is ignored unless Persistent is set to
+	// true. If not set, a default will be chosen by the CRC Operator,
+	// or the cluster's default StorageClass gets used.
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// AccessModes are the access modes of the cluster's persistent
+	// volume. Clusters with a ReadWriteMany volume can be live
+	// migrated between Nodes. This is ignored unless Persistent is
+	// set to true. If not set, a default will be chosen by the CRC
+	// Operator.
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+
+	// VolumeMode is whether the cluster's persistent volume is a
+	// Filesystem holding the disk image or a raw Block device. This
+	// is ignored unless Persistent is set to true. If not set, a
+	// default will be chosen by the CRC Operator.
+	// +kubebuilder:validation:Enum=Filesystem;Block
+	VolumeMode string `json:"volumeMode,omitempty"`
+
+	// AdditionalDisks are extra disks attached to the cluster's
+	// VirtualMachine. Each one gets formatted and offered as a local
+	// PersistentVolume inside the cluster, with the local-storage
+	// StorageClass, so workloads don't fill up the root disk.
+	AdditionalDisks []CrcAdditionalDisk `json:"additionalDisks,omitempty"`
+}
+
+// CrcClusterSource is what a cluster's persistent volume gets
+// created from instead of the bundle. Only one of its fields may be
+// set, and the cluster's storage must be persistent.
+type CrcClusterSource struct {
+	// Snapshot is the name of a Ready CrcClusterSnapshot, in the same
+	// namespace, to restore. The cluster gets the snapshotted
+	// cluster's bundle, credentials, and cluster ID, and only gets
+	// reconfigured for its own name and URLs.
+	Snapshot string `json:"snapshot,omitempty"`
+
+	// CrcCluster is the name of a CrcCluster with persistent storage,
+	// in the same namespace, to clone. It has to stay stopped until
+	// the clone finishes. The clone gets the same bundle and a new
+	// SSH key, kubeadmin password, cluster ID, and URLs.
+	CrcCluster string `json:"crcCluster,omitempty"`
+
+	// PersistentVolumeClaim is a persistent volume claim holding a
+	// cluster's disk to clone. The clone gets a new SSH key,
+	// kubeadmin password, cluster ID, and URLs. The disk has to come
+	// from the cluster's bundle, and either still accept the bundle's
+	// SSH key or have its own SSH key in SSHKeySecretName.
+	PersistentVolumeClaim *CrcClusterSourcePVC `json:"persistentVolumeClaim,omitempty"`
+
+	// Import is a cluster exported by a CrcClusterExport, possibly on
+	// another cluster, to import. The cluster gets the exported
+	// cluster's bundle, credentials, and cluster ID, and only gets
+	// reconfigured for its own name and URLs.
+	Import *CrcClusterSourceImport `json:"import,omitempty"`
+}
+
+// CrcClusterSourceImport is an exported cluster to import
+type CrcClusterSourceImport struct {
+	// URL is where to download the exported cluster's disk image
+	// from, usually the diskURL of a CrcClusterExport
+	URL string `json:"url"`
+
+	// SecretName is the name of a Secret, in the same namespace,
+	// holding the exported cluster's manifest.json. If the URL needs
+	// a username and password, they go in the Secret's accessKeyId
+	// and secretKey, just like in the Secret of a CrcClusterExport.
+	SecretName string `json:"secretName"`
+
+	// CertConfigMapName is the name of a ConfigMap, in the same
+	// namespace, with the CA certificate of an HTTPS URL that isn't
+	// signed by a trusted CA
+	CertConfigMapName string `json:"certConfigMapName,omitempty"`
+}
+
+// CrcClusterSourcePVC is a persistent volume claim to clone
+type CrcClusterSourcePVC struct {
+	// Name is the name of the persistent volume claim
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the persistent volume claim. If
+	// not set, the cluster's namespace gets used. Cloning from another
+	// namespace requires permission to create datavolumes/source
+	// there, checked by the operator's admission webhooks.
+	Namespace string `json:"namespace,omitempty"`
+
+	// SSHKeySecretName is the name of a Secret, in the cluster's
+	// namespace, with the private SSH key the disk accepts in
+	// ssh-privatekey. It's required unless the disk was never booted
+	// as a cluster, since the operator replaces the bundle's SSH key
+	// in every cluster it configures.
+	SSHKeySecretName string `json:"sshKeySecretName,omitempty"`
+}
+
+// CrcEtcdBackupSpec configures scheduled backups of a cluster's etcd
+// to S3-compatible storage
+type CrcEtcdBackupSpec struct {
+	// SecretName is the name of a Secret, in the same namespace,
+	// with the endpoint, bucket, accessKeyId, and secretKey of the
+	// S3-compatible storage to upload backups to, along with an
+	// optional region and prefix for the backups' object keys
+	SecretName string `json:"secretName"`
+
+	// Interval is how often to back up etcd, as a duration like 6h.
+	// If not set, etcd gets backed up once a day.
+	Interval string `json:"interval,omitempty"`
+
+	// Retain is how many of the latest backups to keep. Older ones
+	// get deleted from the bucket after each backup. If not set, 7
+	// backups get kept.
+	Retain int `json:"retain,omitempty"`
+
+	// Restore requests restoring etcd from one of the backups
+	Restore *CrcEtcdRestoreSpec `json:"restore,omitempty"`
+}
+
+// CrcEtcdRestoreSpec requests restoring a cluster's etcd from one of
+// its backups in place
+type CrcEtcdRestoreSpec struct {
+	// Backup is the name of the backup to restore, one of the
+	// cluster's status.etcdBackup.backups
+	Backup string `json:"backup"`
+
+	// RequestedAt requests the restore when set to a time after the
+	// last restore request that was handled
+	RequestedAt metav1.Time `json:"requestedAt"`
+}
+
+// CrcAdditionalDisk is an extra disk attached to a cluster's
+// VirtualMachine
+type CrcAdditionalDisk struct {
+	// Name identifies the disk inside the VirtualMachine and names
+	// its PersistentVolume inside the cluster. Names have to be
+	// unique, and scratch and datavolume are reserved.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=20
+	Name string `json:"name"`
+
+	// Size is the size of the disk
+	Size string `json:"size"`
+
+	// Persistent controls whether the disk is a DataVolume that keeps
+	// its data when the cluster gets stopped or an emptyDisk that
+	// gets wiped. Defaults to false.
+	Persistent bool `json:"persistent,omitempty"`
+
+	// StorageClassName is the StorageClass of a persistent disk. If
+	// not set, the same default as the cluster's own persistent
+	// storage gets used.
+	StorageClassName string `json:"storageClassName,omitempty"`
+}
+
+// CrcPerformanceSpec defines performance tuning of the VirtualMachine
+// of a CrcCluster
+type CrcPerformanceSpec struct {
+	// Sockets is the number of CPU sockets presented to the
+	// cluster. Sockets, Cores, and Threads multiplied together must
+	// equal the cluster's CPU. If not set, this is calculated from
+	// CPU, Cores, and Threads.
+	Sockets int `json:"sockets,omitempty"`
+
+	// Cores is the number of cores per CPU socket. Defaults to 1.
+	Cores int `json:"cores,omitempty"`
+
+	// Threads is the number of threads per CPU core. Defaults to 1.
+	Threads int `json:"threads,omitempty"`
+
+	// CPUModel is the CPU model presented to the cluster, either
+	// host-model to get a CPU model close to the Node's or
+	// host-passthrough to get exactly the Node's CPU. Defaults to
+	// host-model.
+	// +kubebuilder:validation:Enum=host-model;host-passthrough
+	CPUModel string `json:"cpuModel,omitempty"`
+
+	// DedicatedCPUs pins each of the cluster's CPUs to its own CPU on
+	// the Node. This requires Nodes with the CPU manager enabled and
+	// requests all of the cluster's CPU and memory without any
+	// overcommit.
+	DedicatedCPUs bool `json:"dedicatedCPUs,omitempty"`
+
+	// HugepageSize backs the cluster's memory with hugepages of this
+	// size, either 2Mi or 1Gi. The Node must have enough hugepages of
+	// that size preallocated and the cluster's memory must be a
+	// multiple of it.
+	// +kubebuilder:validation:Enum="2Mi";"1Gi"
+	HugepageSize string `json:"hugepageSize,omitempty"`
+
+	// DedicatedIOThread gives the cluster's disk its own IO thread
+	// instead of sharing one with the rest of the VirtualMachine.
+	DedicatedIOThread bool `json:"dedicatedIOThread,omitempty"`
+
+	// DiskCache is the host cache mode of the cluster's disk, either
+	// none or writethrough. If not set, KubeVirt chooses a default.
+	// +kubebuilder:validation:Enum=none;writethrough
+	DiskCache string `json:"diskCache,omitempty"`
 }
 
 const (
@@ -100,8 +398,229 @@ const (
 
 	// ConditionTypeReady indicates if the OpenShift cluster is ready
 	ConditionTypeReady status.ConditionType = "Ready"
+
+	// ConditionTypeQuotaExceeded indicates if the cluster can't be
+	// created or started because it would exceed a CrcClusterQuota
+	ConditionTypeQuotaExceeded status.ConditionType = "QuotaExceeded"
+
+	// ConditionTypeInvalidSize indicates if the cluster can't be
+	// created because it asks for a size that doesn't exist
+	ConditionTypeInvalidSize status.ConditionType = "InvalidSize"
+
+	// ConditionTypePolicyViolation indicates if the cluster can't be
+	// created or started because it violates a CrcClusterPolicy
+	ConditionTypePolicyViolation status.ConditionType = "PolicyViolation"
+
+	// ConditionTypeCredentialsNotRotated indicates if the cluster was
+	// claimed from a CrcClusterPool but its credentials haven't been
+	// rotated for the new owner yet
+	ConditionTypeCredentialsNotRotated status.ConditionType = "CredentialsNotRotated"
+
+	// ConditionTypeCertificatesExpired indicates if the cluster came
+	// back up with expired certificates and is still recovering from
+	// it
+	ConditionTypeCertificatesExpired status.ConditionType = "CertificatesExpired"
+
+	// ConditionTypeResizePending indicates if the cluster's
+	// VirtualMachine is being resized after its CPU, memory, or
+	// storage changed. It's false with the Resized reason once the
+	// latest resize finished.
+	ConditionTypeResizePending status.ConditionType = "ResizePending"
+
+	// ConditionTypeDataVolumeNotReady indicates if the bundle is
+	// still being imported into the cluster's persistent volume, or
+	// failed to be, based on the reason
+	ConditionTypeDataVolumeNotReady status.ConditionType = "DataVolumeNotReady"
+
+	// ConditionTypeSourceNotReady indicates if the cluster is waiting
+	// on the source of its persistent volume, like a
+	// CrcClusterSnapshot, before it can be created
+	ConditionTypeSourceNotReady status.ConditionType = "SourceNotReady"
+
+	// ConditionTypeResetInProgress indicates if the cluster is being
+	// deleted down to its bundle for a factory reset
+	ConditionTypeResetInProgress status.ConditionType = "ResetInProgress"
+
+	// ConditionTypeEtcdBackupFailed indicates if the latest scheduled
+	// etcd backup of the cluster failed
+	ConditionTypeEtcdBackupFailed status.ConditionType = "EtcdBackupFailed"
+
+	// ConditionTypeEtcdRestoreFailed indicates if the latest requested
+	// etcd restore of the cluster failed
+	ConditionTypeEtcdRestoreFailed status.ConditionType = "EtcdRestoreFailed"
+
+	// ConditionTypeQueued indicates if the cluster is waiting for
+	// enough capacity on the Nodes to start
+	ConditionTypeQueued status.ConditionType = "Queued"
+)
+
+// CrcClusterPhase is a simple, high-level summary of where the
+// cluster is in its lifecycle
+type CrcClusterPhase string
+
+const (
+	// CrcClusterPhasePending means the cluster can't be started
+	// because it violates a policy or exceeds a quota
+	CrcClusterPhasePending CrcClusterPhase = "Pending"
+
+	// CrcClusterPhaseQueued means the cluster is waiting for enough
+	// capacity on the Nodes to start
+	CrcClusterPhaseQueued CrcClusterPhase = "Queued"
+
+	// CrcClusterPhaseProvisioning means the cluster is booting or
+	// being configured
+	CrcClusterPhaseProvisioning CrcClusterPhase = "Provisioning"
+
+	// CrcClusterPhaseReady means the cluster is ready to use
+	CrcClusterPhaseReady CrcClusterPhase = "Ready"
+
+	// CrcClusterPhaseStopped means the cluster is stopped
+	CrcClusterPhaseStopped CrcClusterPhase = "Stopped"
+
+	// CrcClusterPhasePaused means the cluster is frozen in memory
+	CrcClusterPhasePaused CrcClusterPhase = "Paused"
 )
 
+// CrcClusterPowerState is whether the cluster's VirtualMachine is
+// running, stopped, or on its way to either
+type CrcClusterPowerState string
+
+const (
+	// CrcClusterPowerStateStarting means the VirtualMachine is
+	// being scheduled or booted
+	CrcClusterPowerStateStarting CrcClusterPowerState = "Starting"
+
+	// CrcClusterPowerStateRunning means the VirtualMachine is running
+	CrcClusterPowerStateRunning CrcClusterPowerState = "Running"
+
+	// CrcClusterPowerStateStopping means the VirtualMachine is
+	// shutting down
+	CrcClusterPowerStateStopping CrcClusterPowerState = "Stopping"
+
+	// CrcClusterPowerStateStopped means the VirtualMachine is not
+	// running
+	CrcClusterPowerStateStopped CrcClusterPowerState = "Stopped"
+
+	// CrcClusterPowerStatePaused means the VirtualMachine is running
+	// but frozen in memory
+	CrcClusterPowerStatePaused CrcClusterPowerState = "Paused"
+)
+
+// CrcClusterShutdownStep is the step a cluster is on while shutting
+// down gracefully
+type CrcClusterShutdownStep string
+
+const (
+	// CrcClusterShutdownStepCordoning means the cluster's Node is
+	// being marked unschedulable
+	CrcClusterShutdownStepCordoning CrcClusterShutdownStep = "Cordoning"
+
+	// CrcClusterShutdownStepStoppingKubelet means kubelet is being
+	// stopped
+	CrcClusterShutdownStepStoppingKubelet CrcClusterShutdownStep = "StoppingKubelet"
+
+	// CrcClusterShutdownStepStoppingContainers means all CRI-O
+	// containers are being stopped
+	CrcClusterShutdownStepStoppingContainers CrcClusterShutdownStep = "StoppingContainers"
+
+	// CrcClusterShutdownStepSyncingFilesystems means the
+	// filesystems are being synced to disk
+	CrcClusterShutdownStepSyncingFilesystems CrcClusterShutdownStep = "SyncingFilesystems"
+
+	// CrcClusterShutdownStepHalting means the VirtualMachine is
+	// being stopped
+	CrcClusterShutdownStepHalting CrcClusterShutdownStep = "Halting"
+)
+
+// CrcClusterDrainPolicy is what happens to a cluster that can't be
+// live migrated when its Node gets drained
+type CrcClusterDrainPolicy string
+
+const (
+	// CrcClusterDrainPolicyBlock keeps the drain from finishing
+	CrcClusterDrainPolicyBlock CrcClusterDrainPolicy = "Block"
+
+	// CrcClusterDrainPolicyStop stops the cluster
+	CrcClusterDrainPolicyStop CrcClusterDrainPolicy = "Stop"
+
+	// CrcClusterDrainPolicyRecreate restarts the cluster on another
+	// Node
+	CrcClusterDrainPolicyRecreate CrcClusterDrainPolicy = "Recreate"
+)
+
+// CrcClusterDataVolumeStatus describes the import of the bundle into
+// a cluster's persistent volume
+type CrcClusterDataVolumeStatus struct {
+	// Name is the name of the DataVolume
+	Name string `json:"name,omitempty"`
+
+	// Phase is the phase of the DataVolume, like ImportScheduled,
+	// ImportInProgress, Succeeded, or Failed
+	Phase string `json:"phase,omitempty"`
+
+	// Progress is how much of the bundle has been imported, as a
+	// percentage
+	Progress string `json:"progress,omitempty"`
+
+	// ImportRestarts is how many times the import was restarted
+	// after failing
+	ImportRestarts int `json:"importRestarts,omitempty"`
+
+	// ImportError is the error of the last failed import attempt
+	ImportError string `json:"importError,omitempty"`
+}
+
+// CrcClusterEtcdBackupStatus is the state of a cluster's etcd backups
+type CrcClusterEtcdBackupStatus struct {
+	// LastBackupTime is when etcd last got backed up successfully
+	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
+
+	// LastAttemptTime is when etcd last got backed up, whether or
+	// not it succeeded
+	LastAttemptTime *metav1.Time `json:"lastAttemptTime,omitempty"`
+
+	// LastBackup is the name of the latest successful backup
+	LastBackup string `json:"lastBackup,omitempty"`
+
+	// Backups lists the names of the backups kept in the bucket,
+	// newest first
+	Backups []string `json:"backups,omitempty"`
+
+	// LastRestoreRequestedAt is the requestedAt of the last restore
+	// request that was handled, whether or not it succeeded
+	LastRestoreRequestedAt *metav1.Time `json:"lastRestoreRequestedAt,omitempty"`
+
+	// LastRestoreTime is when etcd last got restored successfully
+	LastRestoreTime *metav1.Time `json:"lastRestoreTime,omitempty"`
+
+	// LastRestoredBackup is the name of the latest backup restored
+	// successfully
+	LastRestoredBackup string `json:"lastRestoredBackup,omitempty"`
+}
+
+// CrcClusterMigrationStatus describes the latest live migration of a
+// cluster's VirtualMachine to another Node
+type CrcClusterMigrationStatus struct {
+	// Name is the name of the VirtualMachineInstanceMigration
+	Name string `json:"name,omitempty"`
+
+	// Phase is the phase of the VirtualMachineInstanceMigration, like
+	// Scheduling, Running, Succeeded, or Failed
+	Phase string `json:"phase,omitempty"`
+
+	// SourceNode is the Node the cluster is migrating away from
+	SourceNode string `json:"sourceNode,omitempty"`
+
+	// TargetNode is the Node the cluster is migrating to
+	TargetNode string `json:"targetNode,omitempty"`
+
+	// StartTime is when the migration started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// EndTime is when the migration finished
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+}
+
 // CrcClusterStatus defines the observed state of CrcCluster
 type CrcClusterStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -132,13 +651,114 @@ type CrcClusterStatus struct {
 	// KubeAdminPassword is the password to connect to the cluster as an administrator
 	KubeAdminPassword string `json:"kubeAdminPassword,omitempty"`
 
+	// Claim is the name of the CrcClusterClaim this cluster got
+	// handed over to, once its credentials got flagged for rotation
+	Claim string `json:"claim,omitempty"`
+
 	// SSHKey is the unique base64 encoded SSH key used to connect to
 	// this Node after initial setup
 	SSHKey string `json:"sshKey,omitempty"`
 
-	// Stopped indicates whether this cluster is stopped or running
+	// Stopped indicates whether this cluster's VirtualMachine is
+	// actually stopped
 	Stopped bool `json:"stopped,omitempty"`
 
+	// PowerState is whether this cluster's VirtualMachine is running,
+	// stopped, or on its way to either, based on its
+	// VirtualMachineInstance
+	PowerState CrcClusterPowerState `json:"powerState,omitempty"`
+
+	// PowerStateTransitionTime is when PowerState last changed
+	PowerStateTransitionTime *metav1.Time `json:"powerStateTransitionTime,omitempty"`
+
+	// LastStartDuration is how long this cluster's VirtualMachine
+	// spent Starting the last time it started
+	LastStartDuration *metav1.Duration `json:"lastStartDuration,omitempty"`
+
+	// LastCertificateRecoveryTime is when this cluster last finished
+	// recovering from expired certificates
+	LastCertificateRecoveryTime *metav1.Time `json:"lastCertificateRecoveryTime,omitempty"`
+
+	// LastClockSkew is how far off the clock inside this cluster was
+	// the last time it got corrected while starting
+	LastClockSkew *metav1.Duration `json:"lastClockSkew,omitempty"`
+
+	// ShutdownStep is the step this cluster is on while shutting down
+	// gracefully before being stopped
+	ShutdownStep CrcClusterShutdownStep `json:"shutdownStep,omitempty"`
+
+	// ShutdownStartTime is when this cluster started shutting down
+	// gracefully
+	ShutdownStartTime *metav1.Time `json:"shutdownStartTime,omitempty"`
+
+	// LastStopDuration is how long this cluster's VirtualMachine
+	// spent Stopping the last time it stopped
+	LastStopDuration *metav1.Duration `json:"lastStopDuration,omitempty"`
+
+	// ResizeRestartTime is when this cluster's VirtualMachine gets, or
+	// got, restarted to apply a resize
+	ResizeRestartTime *metav1.Time `json:"resizeRestartTime,omitempty"`
+
+	// ResizeChanges lists what the pending resize restart applies,
+	// like CPU, memory, or storage
+	ResizeChanges []string `json:"resizeChanges,omitempty"`
+
+	// DataVolume is the progress of importing the bundle into this
+	// cluster's persistent volume
+	DataVolume *CrcClusterDataVolumeStatus `json:"dataVolume,omitempty"`
+
+	// NodeName is the Node this cluster's VirtualMachine is running
+	// on
+	NodeName string `json:"nodeName,omitempty"`
+
+	// LiveMigratable indicates whether this cluster's VirtualMachine
+	// can be live migrated to another Node. Only clusters with
+	// persistent storage with the ReadWriteMany access mode can be.
+	LiveMigratable bool `json:"liveMigratable,omitempty"`
+
+	// Migration is the latest live migration of this cluster's
+	// VirtualMachine to another Node
+	Migration *CrcClusterMigrationStatus `json:"migration,omitempty"`
+
+	// ConfiguredSteps lists the configuration steps that have been
+	// done inside this cluster. Clusters with persistent storage keep
+	// these between restarts and only verify them when starting
+	// again instead of redoing them.
+	ConfiguredSteps []string `json:"configuredSteps,omitempty"`
+
+	// Phase is a simple, high-level summary of where the cluster is
+	// in its lifecycle
+	Phase CrcClusterPhase `json:"phase,omitempty"`
+
+	// QueuePosition is this cluster's position among all clusters
+	// waiting for capacity on the Nodes, starting at 1. It's only
+	// set while Phase is Queued.
+	QueuePosition int `json:"queuePosition,omitempty"`
+
+	// RequestedCPU is the amount of CPU requested from the Node
+	// running this cluster, after applying any overcommit
+	RequestedCPU string `json:"requestedCPU,omitempty"`
+
+	// RequestedMemory is the amount of memory requested from the Node
+	// running this cluster, after applying any overcommit
+	RequestedMemory string `json:"requestedMemory,omitempty"`
+
+	// LastResetTime is when this cluster last got a factory reset
+	LastResetTime *metav1.Time `json:"lastResetTime,omitempty"`
+
+	// EtcdBackup is the state of this cluster's etcd backups
+	EtcdBackup *CrcClusterEtcdBackupStatus `json:"etcdBackup,omitempty"`
+
+	// LifetimeStartTime is when this cluster's maximum lifetime
+	// started counting, either when a CrcClusterPolicy with a
+	// maximum lifetime first applied to it or when it got claimed
+	// from a pool
+	LifetimeStartTime *metav1.Time `json:"lifetimeStartTime,omitempty"`
+
+	// ExpirationTime is when this cluster will be deleted because it
+	// reached the maximum lifetime allowed by a CrcClusterPolicy
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+
 	// Conditions represent the latest available observations of an object's state
 	Conditions status.Conditions `json:"conditions"`
 }
@@ -182,3 +802,26 @@ func (crc *CrcCluster) SetConditionBool(conditionType status.ConditionType, valu
 	}
 	crc.Status.Conditions.SetCondition(condition)
 }
+
+// SetConditionBoolWithMessage is a helper function to set boolean
+// Conditions along with a reason and human-readable message
+func (crc *CrcCluster) SetConditionBoolWithMessage(conditionType status.ConditionType, value bool, reason status.ConditionReason, message string) {
+	conditionValue := corev1.ConditionFalse
+	if value {
+		conditionValue = corev1.ConditionTrue
+	}
+	condition := status.Condition{
+		Type:    conditionType,
+		Status:  conditionValue,
+		Reason:  reason,
+		Message: message,
+	}
+	crc.Status.Conditions.SetCondition(condition)
+}
+
+// RootVolumeName is the name of the DataVolume, or restored
+// PersistentVolumeClaim, holding the disk of a cluster with
+// persistent storage
+func RootVolumeName(crcName string) string {
+	return fmt.Sprintf("%s-datavolume", crcName)
+}
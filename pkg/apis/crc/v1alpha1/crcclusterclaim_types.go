@@ -0,0 +1,99 @@
+package v1alpha1
+
+import (
+	"github.com/operator-framework/operator-sdk/pkg/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClaimLabel is the label the CRC Operator puts on CrcClusters
+// taken from a CrcClusterPool, set to the name of the claim
+const ClaimLabel = "crc.developer.openshift.io/claim"
+
+// CrcClusterClaimSpec defines the desired state of CrcClusterClaim
+type CrcClusterClaimSpec struct {
+	// PoolName is the name of the CrcClusterPool, in the same
+	// namespace, to claim a cluster from
+	PoolName string `json:"poolName"`
+
+	// PullSecret is the base64-encoded OpenShift pull secret to
+	// switch the claimed cluster to. If not set, the claimed cluster
+	// keeps the pull secret of the pool.
+	PullSecret string `json:"pullSecret,omitempty"`
+}
+
+const (
+	// ConditionTypeClusterNotBound indicates if no cluster from the
+	// pool has been bound to this claim yet
+	ConditionTypeClusterNotBound status.ConditionType = "ClusterNotBound"
+)
+
+// CrcClusterClaimStatus defines the observed state of CrcClusterClaim
+type CrcClusterClaimStatus struct {
+	// ClusterName is the name of the CrcCluster bound to this claim
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// APIURL is the URL of the claimed cluster's API server
+	APIURL string `json:"apiURL,omitempty"`
+
+	// ConsoleURL is the URL of the claimed cluster's web console
+	ConsoleURL string `json:"consoleURL,omitempty"`
+
+	// Kubeconfig is the base64-encoded kubeconfig to connect to the
+	// claimed cluster as an administrator
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+
+	// KubeAdminPassword is the password to connect to the claimed
+	// cluster as an administrator
+	KubeAdminPassword string `json:"kubeAdminPassword,omitempty"`
+
+	// Conditions represent the latest available observations of an object's state
+	Conditions status.Conditions `json:"conditions"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CrcClusterClaim is the Schema for the crcclusterclaims API
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=crcclusterclaims,scope=Namespaced,shortName=crcclaim
+type CrcClusterClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CrcClusterClaimSpec   `json:"spec,omitempty"`
+	Status CrcClusterClaimStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CrcClusterClaimList contains a list of CrcClusterClaim
+type CrcClusterClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CrcClusterClaim `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CrcClusterClaim{}, &CrcClusterClaimList{})
+}
+
+// SetConditionBool is a helper function to set boolean Conditions
+func (claim *CrcClusterClaim) SetConditionBool(conditionType status.ConditionType, value bool) {
+	claim.SetConditionBoolWithMessage(conditionType, value, "", "")
+}
+
+// SetConditionBoolWithMessage is a helper function to set boolean
+// Conditions along with a reason and human-readable message
+func (claim *CrcClusterClaim) SetConditionBoolWithMessage(conditionType status.ConditionType, value bool, reason status.ConditionReason, message string) {
+	conditionValue := corev1.ConditionFalse
+	if value {
+		conditionValue = corev1.ConditionTrue
+	}
+	condition := status.Condition{
+		Type:    conditionType,
+		Status:  conditionValue,
+		Reason:  reason,
+		Message: message,
+	}
+	claim.Status.Conditions.SetCondition(condition)
+}
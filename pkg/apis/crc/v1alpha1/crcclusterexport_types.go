@@ -0,0 +1,121 @@
+package v1alpha1
+
+import (
+	"github.com/operator-framework/operator-sdk/pkg/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ExportAnnotation is the annotation the CRC Operator puts on a
+// CrcCluster to keep it stopped while a CrcClusterExport serves its
+// disk, set to the name of the export
+const ExportAnnotation = "crc.developer.openshift.io/export"
+
+// CrcClusterExportSpec defines the desired state of CrcClusterExport
+type CrcClusterExportSpec struct {
+	// ClusterName is the name of the CrcCluster, in the same
+	// namespace, to export. Only clusters with persistent storage
+	// can be exported. The cluster is kept stopped for as long as
+	// the export exists.
+	ClusterName string `json:"clusterName"`
+}
+
+// CrcClusterExportPhase is where a CrcClusterExport is in exporting
+// its cluster
+type CrcClusterExportPhase string
+
+const (
+	// CrcClusterExportPhasePending means the export hasn't started
+	// yet, usually because the cluster is busy with another export
+	// or isn't provisioned yet
+	CrcClusterExportPhasePending CrcClusterExportPhase = "Pending"
+
+	// CrcClusterExportPhaseQuiescing means the cluster is being shut
+	// down gracefully so its disk is consistent
+	CrcClusterExportPhaseQuiescing CrcClusterExportPhase = "Quiescing"
+
+	// CrcClusterExportPhaseExporting means the server for the
+	// cluster's disk is starting
+	CrcClusterExportPhaseExporting CrcClusterExportPhase = "Exporting"
+
+	// CrcClusterExportPhaseReady means the cluster's disk and manifest
+	// can be downloaded
+	CrcClusterExportPhaseReady CrcClusterExportPhase = "Ready"
+
+	// CrcClusterExportPhaseFailed means the cluster couldn't be
+	// exported
+	CrcClusterExportPhaseFailed CrcClusterExportPhase = "Failed"
+)
+
+// CrcClusterExportStatus defines the observed state of CrcClusterExport
+type CrcClusterExportStatus struct {
+	// Phase is where the export is in exporting its cluster
+	Phase CrcClusterExportPhase `json:"phase,omitempty"`
+
+	// DiskURL is where the cluster's raw disk image can be
+	// downloaded from
+	DiskURL string `json:"diskURL,omitempty"`
+
+	// ManifestURL is where the manifest of the cluster's credentials
+	// and metadata can be downloaded from
+	ManifestURL string `json:"manifestURL,omitempty"`
+
+	// SecretName is the name of the Secret holding the username and
+	// password to download the export with, as accessKeyId and
+	// secretKey, along with the manifest. A copy of it on another
+	// cluster can be used to import the exported cluster there.
+	SecretName string `json:"secretName,omitempty"`
+
+	// ExportTime is when the cluster's disk was ready to download
+	ExportTime *metav1.Time `json:"exportTime,omitempty"`
+
+	// Conditions represent the latest available observations of an object's state
+	Conditions status.Conditions `json:"conditions"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CrcClusterExport is the Schema for the crcclusterexports API
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=crcclusterexports,scope=Namespaced,shortName=crcexport
+type CrcClusterExport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CrcClusterExportSpec   `json:"spec,omitempty"`
+	Status CrcClusterExportStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CrcClusterExportList contains a list of CrcClusterExport
+type CrcClusterExportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CrcClusterExport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CrcClusterExport{}, &CrcClusterExportList{})
+}
+
+// SetConditionBool is a helper function to set boolean Conditions
+func (export *CrcClusterExport) SetConditionBool(conditionType status.ConditionType, value bool) {
+	export.SetConditionBoolWithMessage(conditionType, value, "", "")
+}
+
+// SetConditionBoolWithMessage is a helper function to set boolean
+// Conditions along with a reason and human-readable message
+func (export *CrcClusterExport) SetConditionBoolWithMessage(conditionType status.ConditionType, value bool, reason status.ConditionReason, message string) {
+	conditionValue := corev1.ConditionFalse
+	if value {
+		conditionValue = corev1.ConditionTrue
+	}
+	condition := status.Condition{
+		Type:    conditionType,
+		Status:  conditionValue,
+		Reason:  reason,
+		Message: message,
+	}
+	export.Status.Conditions.SetCondition(condition)
+}
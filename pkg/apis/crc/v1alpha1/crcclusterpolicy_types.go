@@ -0,0 +1,67 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CrcClusterPolicySpec defines the desired state of CrcClusterPolicy
+type CrcClusterPolicySpec struct {
+	// NamespaceSelector selects the namespaces whose CrcClusters
+	// this policy applies to. If not set, the policy applies to
+	// CrcClusters in all namespaces.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// AllowedBundles is the list of CrcBundle names CrcClusters may
+	// use. If empty, any bundle may be used.
+	AllowedBundles []string `json:"allowedBundles,omitempty"`
+
+	// MaxCPU is the maximum number of CPUs a single CrcCluster may
+	// request. If not set, CPU is not limited.
+	MaxCPU *int `json:"maxCPU,omitempty"`
+
+	// MaxMemory is the maximum amount of memory a single CrcCluster
+	// may request. If not set, memory is not limited.
+	MaxMemory string `json:"maxMemory,omitempty"`
+
+	// AllowPersistentStorage controls whether CrcClusters may use
+	// persistent storage. Defaults to true.
+	AllowPersistentStorage *bool `json:"allowPersistentStorage,omitempty"`
+
+	// AllowBundleImage controls whether CrcClusters may set
+	// bundleImage to an image that doesn't match one of the known
+	// CrcBundles. Such clusters are set up with the SSH key and
+	// kubeconfig of an existing bundle, so only allow this for
+	// trusted users. Defaults to false.
+	AllowBundleImage bool `json:"allowBundleImage,omitempty"`
+
+	// MaxLifetime is how long a CrcCluster may exist before the
+	// operator deletes it, as a duration string such as "72h". It
+	// counts from when a policy with a maximum lifetime first applied
+	// to the cluster, or from when the cluster got claimed from a
+	// pool. If not set, clusters are never deleted automatically.
+	MaxLifetime *metav1.Duration `json:"maxLifetime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CrcClusterPolicy is the Schema for the crcclusterpolicies API
+// +kubebuilder:resource:path=crcclusterpolicies,scope=Cluster,shortName=crcpolicy
+type CrcClusterPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CrcClusterPolicySpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CrcClusterPolicyList contains a list of CrcClusterPolicy
+type CrcClusterPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CrcClusterPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CrcClusterPolicy{}, &CrcClusterPolicyList{})
+}
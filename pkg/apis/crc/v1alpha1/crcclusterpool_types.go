@@ -0,0 +1,56 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PoolLabel is the label the CRC Operator puts on unclaimed
+// CrcClusters in a CrcClusterPool, set to the name of the pool
+const PoolLabel = "crc.developer.openshift.io/pool"
+
+// CrcClusterPoolSpec defines the desired state of CrcClusterPool
+type CrcClusterPoolSpec struct {
+	// Size is the number of unclaimed CrcClusters to keep in this
+	// pool.
+	// +kubebuilder:validation:Minimum=0
+	Size int `json:"size"`
+
+	// Template is the spec used to create CrcClusters in this pool
+	Template CrcClusterSpec `json:"template"`
+}
+
+// CrcClusterPoolStatus defines the observed state of CrcClusterPool
+type CrcClusterPoolStatus struct {
+	// Clusters is the number of unclaimed CrcClusters in this pool
+	Clusters int `json:"clusters"`
+
+	// Ready is the number of unclaimed CrcClusters in this pool that
+	// are Ready to be claimed
+	Ready int `json:"ready"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CrcClusterPool is the Schema for the crcclusterpools API
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=crcclusterpools,scope=Namespaced,shortName=crcpool
+type CrcClusterPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CrcClusterPoolSpec   `json:"spec,omitempty"`
+	Status CrcClusterPoolStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CrcClusterPoolList contains a list of CrcClusterPool
+type CrcClusterPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CrcClusterPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CrcClusterPool{}, &CrcClusterPoolList{})
+}
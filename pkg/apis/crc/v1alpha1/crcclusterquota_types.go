@@ -0,0 +1,113 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CrcClusterQuotaScope is the set of CrcClusters a quota applies to
+type CrcClusterQuotaScope string
+
+const (
+	// CrcClusterQuotaScopeNamespace limits all CrcClusters in the
+	// quota's namespace combined
+	CrcClusterQuotaScopeNamespace CrcClusterQuotaScope = "Namespace"
+
+	// CrcClusterQuotaScopeUser limits the CrcClusters of each
+	// requesting user in the quota's namespace individually
+	CrcClusterQuotaScopeUser CrcClusterQuotaScope = "User"
+)
+
+// RequesterAnnotation is the annotation the CRC Operator uses to
+// record which user requested a CrcCluster
+const RequesterAnnotation = "crc.developer.openshift.io/requester"
+
+// CrcClusterQuotaSpec defines the desired state of CrcClusterQuota
+type CrcClusterQuotaSpec struct {
+	// Scope controls whether these limits apply to all CrcClusters in
+	// this namespace combined (Namespace) or to the CrcClusters of
+	// each requesting user in this namespace individually
+	// (User). Defaults to Namespace.
+	// +kubebuilder:validation:Enum=Namespace;User
+	Scope CrcClusterQuotaScope `json:"scope,omitempty"`
+
+	// Clusters is the maximum number of CrcClusters, running or
+	// stopped. If not set, the number of clusters is not limited.
+	Clusters *int `json:"clusters,omitempty"`
+
+	// CPU is the maximum total number of CPUs allocated to running
+	// CrcClusters. If not set, CPU is not limited.
+	CPU *int `json:"cpu,omitempty"`
+
+	// Memory is the maximum total amount of memory allocated to
+	// running CrcClusters. If not set, memory is not limited.
+	Memory string `json:"memory,omitempty"`
+
+	// Storage is the maximum total amount of persistent disk space
+	// allocated to CrcClusters, running or stopped. If not set,
+	// storage is not limited.
+	Storage string `json:"storage,omitempty"`
+}
+
+// CrcClusterQuotaUsage is the amount of resources used by the
+// CrcClusters covered by a quota
+type CrcClusterQuotaUsage struct {
+	// Clusters is the number of CrcClusters
+	Clusters int `json:"clusters"`
+
+	// CPU is the total number of CPUs allocated to running CrcClusters
+	CPU int `json:"cpu"`
+
+	// Memory is the total amount of memory allocated to running
+	// CrcClusters
+	Memory string `json:"memory,omitempty"`
+
+	// Storage is the total amount of persistent disk space allocated
+	// to CrcClusters
+	Storage string `json:"storage,omitempty"`
+}
+
+// CrcClusterQuotaUserUsage is the amount of resources used by the
+// CrcClusters of a single requesting user
+type CrcClusterQuotaUserUsage struct {
+	// User is the name of the requesting user
+	User string `json:"user"`
+
+	CrcClusterQuotaUsage `json:",inline"`
+}
+
+// CrcClusterQuotaStatus defines the observed state of CrcClusterQuota
+type CrcClusterQuotaStatus struct {
+	// Used is the total amount of resources used by all CrcClusters
+	// in this namespace
+	Used CrcClusterQuotaUsage `json:"used,omitempty"`
+
+	// Users is the amount of resources used by each requesting
+	// user. This is only populated when Scope is User.
+	Users []CrcClusterQuotaUserUsage `json:"users,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CrcClusterQuota is the Schema for the crcclusterquotas API
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=crcclusterquotas,scope=Namespaced,shortName=crcquota
+type CrcClusterQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CrcClusterQuotaSpec   `json:"spec,omitempty"`
+	Status CrcClusterQuotaStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CrcClusterQuotaList contains a list of CrcClusterQuota
+type CrcClusterQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CrcClusterQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CrcClusterQuota{}, &CrcClusterQuotaList{})
+}
@@ -0,0 +1,144 @@
+package v1alpha1
+
+import (
+	"github.com/operator-framework/operator-sdk/pkg/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SnapshotAnnotation is the annotation the CRC Operator puts on a
+// CrcCluster while it's stopped to take a CrcClusterSnapshot, set to
+// the name of the snapshot
+const SnapshotAnnotation = "crc.developer.openshift.io/snapshot"
+
+// CrcClusterSnapshotSpec defines the desired state of CrcClusterSnapshot
+type CrcClusterSnapshotSpec struct {
+	// ClusterName is the name of the CrcCluster, in the same
+	// namespace, to snapshot. Only clusters with persistent storage
+	// can be snapshotted.
+	ClusterName string `json:"clusterName"`
+
+	// VolumeSnapshotClassName is the VolumeSnapshotClass of the
+	// snapshot of the cluster's persistent volume. If not set, the
+	// default VolumeSnapshotClass gets used.
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+}
+
+// CrcClusterSnapshotPhase is where a CrcClusterSnapshot is in taking
+// the snapshot
+type CrcClusterSnapshotPhase string
+
+const (
+	// CrcClusterSnapshotPhasePending means the snapshot hasn't
+	// started yet, usually because the cluster is busy with another
+	// snapshot
+	CrcClusterSnapshotPhasePending CrcClusterSnapshotPhase = "Pending"
+
+	// CrcClusterSnapshotPhaseQuiescing means the cluster is being
+	// shut down gracefully so its disk is consistent
+	CrcClusterSnapshotPhaseQuiescing CrcClusterSnapshotPhase = "Quiescing"
+
+	// CrcClusterSnapshotPhaseSnapshotting means the VolumeSnapshot
+	// of the cluster's persistent volume is being taken. The
+	// cluster gets started again as soon as the snapshot is cut,
+	// even if it isn't ready to use yet.
+	CrcClusterSnapshotPhaseSnapshotting CrcClusterSnapshotPhase = "Snapshotting"
+
+	// CrcClusterSnapshotPhaseReady means the snapshot can be
+	// restored
+	CrcClusterSnapshotPhaseReady CrcClusterSnapshotPhase = "Ready"
+
+	// CrcClusterSnapshotPhaseFailed means the snapshot couldn't be
+	// taken
+	CrcClusterSnapshotPhaseFailed CrcClusterSnapshotPhase = "Failed"
+)
+
+// CrcClusterSnapshotStatus defines the observed state of CrcClusterSnapshot
+type CrcClusterSnapshotStatus struct {
+	// Phase is where the snapshot is in being taken
+	Phase CrcClusterSnapshotPhase `json:"phase,omitempty"`
+
+	// VolumeSnapshotName is the name of the VolumeSnapshot of the
+	// cluster's persistent volume
+	VolumeSnapshotName string `json:"volumeSnapshotName,omitempty"`
+
+	// SnapshotTime is when the cluster's persistent volume was
+	// snapshotted
+	SnapshotTime *metav1.Time `json:"snapshotTime,omitempty"`
+
+	// RestoreSize is the minimum size of a persistent volume
+	// restored from this snapshot
+	RestoreSize string `json:"restoreSize,omitempty"`
+
+	// BundleName is the name of the bundle the cluster was created
+	// from
+	BundleName string `json:"bundleName,omitempty"`
+
+	// BundleImage is the bundle image the cluster was created from,
+	// if it overrode the image of its bundle
+	BundleImage string `json:"bundleImage,omitempty"`
+
+	// ClusterID is the ID of the snapshotted cluster
+	ClusterID string `json:"clusterID,omitempty"`
+
+	// KubeAdminPassword is the password to connect to the
+	// snapshotted cluster as an administrator
+	KubeAdminPassword string `json:"kubeAdminPassword,omitempty"`
+
+	// KubeAdminClientKey is the base64-encoded client key to connect
+	// to the snapshotted cluster as an administrator
+	KubeAdminClientKey string `json:"kubeAdminClientKey,omitempty"`
+
+	// SSHKey is the base64-encoded SSH key of the snapshotted cluster
+	SSHKey string `json:"sshKey,omitempty"`
+
+	// Conditions represent the latest available observations of an object's state
+	Conditions status.Conditions `json:"conditions"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CrcClusterSnapshot is the Schema for the crcclustersnapshots API
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=crcclustersnapshots,scope=Namespaced,shortName=crcsnap
+type CrcClusterSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CrcClusterSnapshotSpec   `json:"spec,omitempty"`
+	Status CrcClusterSnapshotStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CrcClusterSnapshotList contains a list of CrcClusterSnapshot
+type CrcClusterSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CrcClusterSnapshot `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CrcClusterSnapshot{}, &CrcClusterSnapshotList{})
+}
+
+// SetConditionBool is a helper function to set boolean Conditions
+func (snapshot *CrcClusterSnapshot) SetConditionBool(conditionType status.ConditionType, value bool) {
+	snapshot.SetConditionBoolWithMessage(conditionType, value, "", "")
+}
+
+// SetConditionBoolWithMessage is a helper function to set boolean
+// Conditions along with a reason and human-readable message
+func (snapshot *CrcClusterSnapshot) SetConditionBoolWithMessage(conditionType status.ConditionType, value bool, reason status.ConditionReason, message string) {
+	conditionValue := corev1.ConditionFalse
+	if value {
+		conditionValue = corev1.ConditionTrue
+	}
+	condition := status.Condition{
+		Type:    conditionType,
+		Status:  conditionValue,
+		Reason:  reason,
+		Message: message,
+	}
+	snapshot.Status.Conditions.SetCondition(condition)
+}
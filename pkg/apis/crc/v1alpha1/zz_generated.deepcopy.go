@@ -6,15 +6,33 @@ package v1alpha1
 
 import (
 	status "github.com/operator-framework/operator-sdk/pkg/status"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcAdditionalDisk) DeepCopyInto(out *CrcAdditionalDisk) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcAdditionalDisk.
+func (in *CrcAdditionalDisk) DeepCopy() *CrcAdditionalDisk {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcAdditionalDisk)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CrcBundle) DeepCopyInto(out *CrcBundle) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 	return
 }
@@ -73,6 +91,7 @@ func (in *CrcBundleList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CrcBundleSpec) DeepCopyInto(out *CrcBundleSpec) {
 	*out = *in
+	in.Overcommit.DeepCopyInto(&out.Overcommit)
 	return
 }
 
@@ -130,6 +149,263 @@ func (in *CrcCluster) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterClaim) DeepCopyInto(out *CrcClusterClaim) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterClaim.
+func (in *CrcClusterClaim) DeepCopy() *CrcClusterClaim {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterClaim)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CrcClusterClaim) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterClaimList) DeepCopyInto(out *CrcClusterClaimList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CrcClusterClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterClaimList.
+func (in *CrcClusterClaimList) DeepCopy() *CrcClusterClaimList {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterClaimList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CrcClusterClaimList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterClaimSpec) DeepCopyInto(out *CrcClusterClaimSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterClaimSpec.
+func (in *CrcClusterClaimSpec) DeepCopy() *CrcClusterClaimSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterClaimSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterClaimStatus) DeepCopyInto(out *CrcClusterClaimStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(status.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterClaimStatus.
+func (in *CrcClusterClaimStatus) DeepCopy() *CrcClusterClaimStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterClaimStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterDataVolumeStatus) DeepCopyInto(out *CrcClusterDataVolumeStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterDataVolumeStatus.
+func (in *CrcClusterDataVolumeStatus) DeepCopy() *CrcClusterDataVolumeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterDataVolumeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterEtcdBackupStatus) DeepCopyInto(out *CrcClusterEtcdBackupStatus) {
+	*out = *in
+	if in.LastBackupTime != nil {
+		in, out := &in.LastBackupTime, &out.LastBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastAttemptTime != nil {
+		in, out := &in.LastAttemptTime, &out.LastAttemptTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Backups != nil {
+		in, out := &in.Backups, &out.Backups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastRestoreRequestedAt != nil {
+		in, out := &in.LastRestoreRequestedAt, &out.LastRestoreRequestedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRestoreTime != nil {
+		in, out := &in.LastRestoreTime, &out.LastRestoreTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterEtcdBackupStatus.
+func (in *CrcClusterEtcdBackupStatus) DeepCopy() *CrcClusterEtcdBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterEtcdBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterExport) DeepCopyInto(out *CrcClusterExport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterExport.
+func (in *CrcClusterExport) DeepCopy() *CrcClusterExport {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CrcClusterExport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterExportList) DeepCopyInto(out *CrcClusterExportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CrcClusterExport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterExportList.
+func (in *CrcClusterExportList) DeepCopy() *CrcClusterExportList {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterExportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CrcClusterExportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterExportSpec) DeepCopyInto(out *CrcClusterExportSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterExportSpec.
+func (in *CrcClusterExportSpec) DeepCopy() *CrcClusterExportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterExportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterExportStatus) DeepCopyInto(out *CrcClusterExportStatus) {
+	*out = *in
+	if in.ExportTime != nil {
+		in, out := &in.ExportTime, &out.ExportTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(status.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterExportStatus.
+func (in *CrcClusterExportStatus) DeepCopy() *CrcClusterExportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterExportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CrcClusterList) DeepCopyInto(out *CrcClusterList) {
 	*out = *in
@@ -164,33 +440,64 @@ func (in *CrcClusterList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CrcClusterSpec) DeepCopyInto(out *CrcClusterSpec) {
+func (in *CrcClusterMigrationStatus) DeepCopyInto(out *CrcClusterMigrationStatus) {
 	*out = *in
-	out.Storage = in.Storage
-	if in.EnableMonitoring != nil {
-		in, out := &in.EnableMonitoring, &out.EnableMonitoring
-		*out = new(bool)
-		**out = **in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EndTime != nil {
+		in, out := &in.EndTime, &out.EndTime
+		*out = (*in).DeepCopy()
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterSpec.
-func (in *CrcClusterSpec) DeepCopy() *CrcClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterMigrationStatus.
+func (in *CrcClusterMigrationStatus) DeepCopy() *CrcClusterMigrationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(CrcClusterSpec)
+	out := new(CrcClusterMigrationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CrcClusterStatus) DeepCopyInto(out *CrcClusterStatus) {
+func (in *CrcClusterPolicy) DeepCopyInto(out *CrcClusterPolicy) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make(status.Conditions, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterPolicy.
+func (in *CrcClusterPolicy) DeepCopy() *CrcClusterPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CrcClusterPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterPolicyList) DeepCopyInto(out *CrcClusterPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CrcClusterPolicy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -198,19 +505,720 @@ func (in *CrcClusterStatus) DeepCopyInto(out *CrcClusterStatus) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterStatus.
-func (in *CrcClusterStatus) DeepCopy() *CrcClusterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterPolicyList.
+func (in *CrcClusterPolicyList) DeepCopy() *CrcClusterPolicyList {
 	if in == nil {
 		return nil
 	}
-	out := new(CrcClusterStatus)
+	out := new(CrcClusterPolicyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CrcClusterPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CrcStorageSpec) DeepCopyInto(out *CrcStorageSpec) {
+func (in *CrcClusterPolicySpec) DeepCopyInto(out *CrcClusterPolicySpec) {
 	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedBundles != nil {
+		in, out := &in.AllowedBundles, &out.AllowedBundles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxCPU != nil {
+		in, out := &in.MaxCPU, &out.MaxCPU
+		*out = new(int)
+		**out = **in
+	}
+	if in.AllowPersistentStorage != nil {
+		in, out := &in.AllowPersistentStorage, &out.AllowPersistentStorage
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxLifetime != nil {
+		in, out := &in.MaxLifetime, &out.MaxLifetime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterPolicySpec.
+func (in *CrcClusterPolicySpec) DeepCopy() *CrcClusterPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterPool) DeepCopyInto(out *CrcClusterPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterPool.
+func (in *CrcClusterPool) DeepCopy() *CrcClusterPool {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CrcClusterPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterPoolList) DeepCopyInto(out *CrcClusterPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CrcClusterPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterPoolList.
+func (in *CrcClusterPoolList) DeepCopy() *CrcClusterPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CrcClusterPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterPoolSpec) DeepCopyInto(out *CrcClusterPoolSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterPoolSpec.
+func (in *CrcClusterPoolSpec) DeepCopy() *CrcClusterPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterPoolStatus) DeepCopyInto(out *CrcClusterPoolStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterPoolStatus.
+func (in *CrcClusterPoolStatus) DeepCopy() *CrcClusterPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterQuota) DeepCopyInto(out *CrcClusterQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterQuota.
+func (in *CrcClusterQuota) DeepCopy() *CrcClusterQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CrcClusterQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterQuotaList) DeepCopyInto(out *CrcClusterQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CrcClusterQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterQuotaList.
+func (in *CrcClusterQuotaList) DeepCopy() *CrcClusterQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterQuotaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CrcClusterQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterQuotaSpec) DeepCopyInto(out *CrcClusterQuotaSpec) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = new(int)
+		**out = **in
+	}
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterQuotaSpec.
+func (in *CrcClusterQuotaSpec) DeepCopy() *CrcClusterQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterQuotaStatus) DeepCopyInto(out *CrcClusterQuotaStatus) {
+	*out = *in
+	out.Used = in.Used
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]CrcClusterQuotaUserUsage, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterQuotaStatus.
+func (in *CrcClusterQuotaStatus) DeepCopy() *CrcClusterQuotaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterQuotaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterQuotaUsage) DeepCopyInto(out *CrcClusterQuotaUsage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterQuotaUsage.
+func (in *CrcClusterQuotaUsage) DeepCopy() *CrcClusterQuotaUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterQuotaUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterQuotaUserUsage) DeepCopyInto(out *CrcClusterQuotaUserUsage) {
+	*out = *in
+	out.CrcClusterQuotaUsage = in.CrcClusterQuotaUsage
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterQuotaUserUsage.
+func (in *CrcClusterQuotaUserUsage) DeepCopy() *CrcClusterQuotaUserUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterQuotaUserUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterSnapshot) DeepCopyInto(out *CrcClusterSnapshot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterSnapshot.
+func (in *CrcClusterSnapshot) DeepCopy() *CrcClusterSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CrcClusterSnapshot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterSnapshotList) DeepCopyInto(out *CrcClusterSnapshotList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CrcClusterSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterSnapshotList.
+func (in *CrcClusterSnapshotList) DeepCopy() *CrcClusterSnapshotList {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterSnapshotList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CrcClusterSnapshotList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterSnapshotSpec) DeepCopyInto(out *CrcClusterSnapshotSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterSnapshotSpec.
+func (in *CrcClusterSnapshotSpec) DeepCopy() *CrcClusterSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterSnapshotStatus) DeepCopyInto(out *CrcClusterSnapshotStatus) {
+	*out = *in
+	if in.SnapshotTime != nil {
+		in, out := &in.SnapshotTime, &out.SnapshotTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(status.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterSnapshotStatus.
+func (in *CrcClusterSnapshotStatus) DeepCopy() *CrcClusterSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterSource) DeepCopyInto(out *CrcClusterSource) {
+	*out = *in
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(CrcClusterSourcePVC)
+		**out = **in
+	}
+	if in.Import != nil {
+		in, out := &in.Import, &out.Import
+		*out = new(CrcClusterSourceImport)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterSource.
+func (in *CrcClusterSource) DeepCopy() *CrcClusterSource {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterSourceImport) DeepCopyInto(out *CrcClusterSourceImport) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterSourceImport.
+func (in *CrcClusterSourceImport) DeepCopy() *CrcClusterSourceImport {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterSourceImport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterSourcePVC) DeepCopyInto(out *CrcClusterSourcePVC) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterSourcePVC.
+func (in *CrcClusterSourcePVC) DeepCopy() *CrcClusterSourcePVC {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterSourcePVC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterSpec) DeepCopyInto(out *CrcClusterSpec) {
+	*out = *in
+	in.Storage.DeepCopyInto(&out.Storage)
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(CrcClusterSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResetRequestedAt != nil {
+		in, out := &in.ResetRequestedAt, &out.ResetRequestedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.EtcdBackup != nil {
+		in, out := &in.EtcdBackup, &out.EtcdBackup
+		*out = new(CrcEtcdBackupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ShutdownGracePeriod != nil {
+		in, out := &in.ShutdownGracePeriod, &out.ShutdownGracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.EnableMonitoring != nil {
+		in, out := &in.EnableMonitoring, &out.EnableMonitoring
+		*out = new(bool)
+		**out = **in
+	}
+	in.Placement.DeepCopyInto(&out.Placement)
+	out.Performance = in.Performance
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterSpec.
+func (in *CrcClusterSpec) DeepCopy() *CrcClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcClusterStatus) DeepCopyInto(out *CrcClusterStatus) {
+	*out = *in
+	if in.PowerStateTransitionTime != nil {
+		in, out := &in.PowerStateTransitionTime, &out.PowerStateTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastStartDuration != nil {
+		in, out := &in.LastStartDuration, &out.LastStartDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.LastCertificateRecoveryTime != nil {
+		in, out := &in.LastCertificateRecoveryTime, &out.LastCertificateRecoveryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastClockSkew != nil {
+		in, out := &in.LastClockSkew, &out.LastClockSkew
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ShutdownStartTime != nil {
+		in, out := &in.ShutdownStartTime, &out.ShutdownStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastStopDuration != nil {
+		in, out := &in.LastStopDuration, &out.LastStopDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ResizeRestartTime != nil {
+		in, out := &in.ResizeRestartTime, &out.ResizeRestartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ResizeChanges != nil {
+		in, out := &in.ResizeChanges, &out.ResizeChanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DataVolume != nil {
+		in, out := &in.DataVolume, &out.DataVolume
+		*out = new(CrcClusterDataVolumeStatus)
+		**out = **in
+	}
+	if in.Migration != nil {
+		in, out := &in.Migration, &out.Migration
+		*out = new(CrcClusterMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfiguredSteps != nil {
+		in, out := &in.ConfiguredSteps, &out.ConfiguredSteps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastResetTime != nil {
+		in, out := &in.LastResetTime, &out.LastResetTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EtcdBackup != nil {
+		in, out := &in.EtcdBackup, &out.EtcdBackup
+		*out = new(CrcClusterEtcdBackupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LifetimeStartTime != nil {
+		in, out := &in.LifetimeStartTime, &out.LifetimeStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(status.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcClusterStatus.
+func (in *CrcClusterStatus) DeepCopy() *CrcClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcEtcdBackupSpec) DeepCopyInto(out *CrcEtcdBackupSpec) {
+	*out = *in
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(CrcEtcdRestoreSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcEtcdBackupSpec.
+func (in *CrcEtcdBackupSpec) DeepCopy() *CrcEtcdBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcEtcdBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcEtcdRestoreSpec) DeepCopyInto(out *CrcEtcdRestoreSpec) {
+	*out = *in
+	in.RequestedAt.DeepCopyInto(&out.RequestedAt)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcEtcdRestoreSpec.
+func (in *CrcEtcdRestoreSpec) DeepCopy() *CrcEtcdRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcEtcdRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcOvercommitSpec) DeepCopyInto(out *CrcOvercommitSpec) {
+	*out = *in
+	if in.GuestOverhead != nil {
+		in, out := &in.GuestOverhead, &out.GuestOverhead
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcOvercommitSpec.
+func (in *CrcOvercommitSpec) DeepCopy() *CrcOvercommitSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcOvercommitSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcPerformanceSpec) DeepCopyInto(out *CrcPerformanceSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcPerformanceSpec.
+func (in *CrcPerformanceSpec) DeepCopy() *CrcPerformanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcPerformanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcPlacementSpec) DeepCopyInto(out *CrcPlacementSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrcPlacementSpec.
+func (in *CrcPlacementSpec) DeepCopy() *CrcPlacementSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrcPlacementSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrcStorageSpec) DeepCopyInto(out *CrcStorageSpec) {
+	*out = *in
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdditionalDisks != nil {
+		in, out := &in.AdditionalDisks, &out.AdditionalDisks
+		*out = make([]CrcAdditionalDisk, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
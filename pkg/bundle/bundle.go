@@ -0,0 +1,76 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var defaultBundleName = os.Getenv("DEFAULT_BUNDLE_NAME")
+var bundleNs = os.Getenv("POD_NAMESPACE")
+
+// ForCrcCluster returns the CrcBundle used by the given CrcCluster.
+// The returned bool is true if the CrcCluster's bundleImage doesn't
+// match any known bundle and instead overrides the image of the
+// bundle found by name.
+func ForCrcCluster(c client.Client, crc *crcv1alpha1.CrcCluster) (*crcv1alpha1.CrcBundle, bool, error) {
+	bundleName := crc.Spec.BundleName
+	if bundleName == "" {
+		bundleName = defaultBundleName
+	}
+	bundleImage := crc.Spec.BundleImage
+
+	// First, see if a BundleImage was given and exactly matches one
+	// of the predefined bundle images
+	if bundleImage != "" {
+		bundle, err := FromImage(c, bundleImage)
+		if err == nil {
+			return bundle, false, nil
+		}
+	}
+	// Now, attempt to find the bundle by name
+	bundle, err := FromName(c, bundleName)
+	if err != nil {
+		return nil, false, err
+	}
+	if bundleImage != "" {
+		bundle.Spec.Image = bundleImage
+		return bundle, true, nil
+	}
+	return bundle, false, nil
+}
+
+// FromImage returns the CrcBundle with the given image
+func FromImage(c client.Client, image string) (*crcv1alpha1.CrcBundle, error) {
+	bundleList := &crcv1alpha1.CrcBundleList{}
+	err := c.List(context.TODO(), bundleList, &client.ListOptions{Namespace: bundleNs})
+	if err != nil {
+		return nil, err
+	}
+	for _, bundle := range bundleList.Items {
+		if image == bundle.Spec.Image {
+			copiedBundle := bundle
+			return &copiedBundle, nil
+		}
+	}
+	return nil, fmt.Errorf("No known bundle matches image %s", image)
+}
+
+// FromName returns the CrcBundle with the given name
+func FromName(c client.Client, name string) (*crcv1alpha1.CrcBundle, error) {
+	bundleList := &crcv1alpha1.CrcBundleList{}
+	err := c.List(context.TODO(), bundleList, &client.ListOptions{Namespace: bundleNs})
+	if err != nil {
+		return nil, err
+	}
+	for _, bundle := range bundleList.Items {
+		if name == bundle.Name {
+			copiedBundle := bundle
+			return &copiedBundle, nil
+		}
+	}
+	return nil, fmt.Errorf("No known bundle matches name %s", name)
+}
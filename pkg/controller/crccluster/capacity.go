@@ -0,0 +1,410 @@
+package crccluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxBootingClusters is the maximum number of clusters allowed to be
+// provisioning at once across all namespaces, to avoid every Node
+// pulling bundle images at the same time. 0 means no limit.
+var maxBootingClusters = os.Getenv("MAX_BOOTING_CLUSTERS")
+
+// schedulableNodeLabel is the label KubeVirt puts on Nodes that can
+// run VirtualMachines
+const schedulableNodeLabel = "kubevirt.io/schedulable"
+
+// needsAdmission returns true if the cluster's VirtualMachine is about
+// to be created or started, given the existing VirtualMachine or nil
+// if there is none. Only these need to fit within quotas and
+// capacity - clusters already running keep running.
+func needsAdmission(crc *crcv1alpha1.CrcCluster, vm *kubevirtv1.VirtualMachine) bool {
+	if vm == nil {
+		return true
+	}
+	return runStrategyForCrcCluster(crc) != kubevirtv1.RunStrategyHalted && vmHalted(vm)
+}
+
+// admitByCapacity checks whether there's room on the Nodes to create
+// or start the VirtualMachine for this cluster. Clusters that don't
+// fit, or that are behind others waiting for room, are put in the
+// Queued phase. It returns false if the cluster must keep waiting.
+func (r *ReconcileCrcCluster) admitByCapacity(logger logr.Logger, crc *crcv1alpha1.CrcCluster, bundle *crcv1alpha1.CrcBundle) (*crcv1alpha1.CrcCluster, bool, error) {
+	maxBooting := 0
+	if maxBootingClusters != "" {
+		var err error
+		maxBooting, err = strconv.Atoi(maxBootingClusters)
+		if err != nil {
+			logger.Error(err, "Invalid MAX_BOOTING_CLUSTERS environment variable.")
+			return crc, false, err
+		}
+	}
+
+	crcList := &crcv1alpha1.CrcClusterList{}
+	if err := r.client.List(context.TODO(), crcList); err != nil {
+		logger.Error(err, "Failed to list CrcClusters.")
+		return crc, false, err
+	}
+	vmList := &kubevirtv1.VirtualMachineList{}
+	if err := r.client.List(context.TODO(), vmList); err != nil {
+		logger.Error(err, "Failed to list VirtualMachines.")
+		return crc, false, err
+	}
+	vms := map[types.NamespacedName]*kubevirtv1.VirtualMachine{}
+	for i := range vmList.Items {
+		vm := &vmList.Items[i]
+		vms[types.NamespacedName{Name: vm.Name, Namespace: vm.Namespace}] = vm
+	}
+	vmiList := &kubevirtv1.VirtualMachineInstanceList{}
+	if err := r.client.List(context.TODO(), vmiList, client.HasLabels{"crcCluster"}); err != nil {
+		logger.Error(err, "Failed to list VirtualMachineInstances.")
+		return crc, false, err
+	}
+	vmis := map[types.NamespacedName]*kubevirtv1.VirtualMachineInstance{}
+	for i := range vmiList.Items {
+		vmi := &vmiList.Items[i]
+		vmis[types.NamespacedName{Name: vmi.Name, Namespace: vmi.Namespace}] = vmi
+	}
+
+	queue := newAdmissionQueue(crc, crcList.Items, vms, vmis)
+	position := queue.position(crc)
+	if position == 0 {
+		// We don't need to wait on anything
+		crc.Status.QueuePosition = 0
+		crc.SetConditionBool(crcv1alpha1.ConditionTypeQueued, false)
+		return crc, true, nil
+	}
+
+	capacity, err := r.newNodeCapacity()
+	if err != nil {
+		logger.Error(err, "Failed to calculate Node capacity.")
+		return crc, false, err
+	}
+	reason, err := queue.admit(logger, crc, maxBooting, func(queuedCrc *crcv1alpha1.CrcCluster) (bool, error) {
+		if queuedCrc.UID == crc.UID {
+			return capacity.reserve(crc, bundle)
+		}
+		queuedBundle, err := r.bundleForCrc(queuedCrc)
+		if err != nil {
+			return false, err
+		}
+		return capacity.reserve(queuedCrc, queuedBundle)
+	})
+	if err != nil {
+		logger.Error(err, "Failed to calculate Node capacity.")
+		return crc, false, err
+	}
+
+	if reason == "" {
+		// Record that we're provisioning right away so other clusters
+		// count us against the maximum booting clusters
+		crc.Status.QueuePosition = 0
+		crc.SetConditionBool(crcv1alpha1.ConditionTypeQueued, false)
+		crc, err := r.updateCrcClusterStatus(crc)
+		return crc, err == nil, err
+	}
+
+	logger.Info("Queueing CrcCluster.", "QueuePosition", position, "Reason", reason)
+	crc.Status.QueuePosition = position
+	crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeQueued, true, "InsufficientCapacity", reason)
+	crc, err = r.updateCrcClusterStatus(crc)
+	return crc, false, err
+}
+
+// admissionQueue holds the clusters waiting to be created or started,
+// in the order they get admitted, along with the clusters already
+// admitted that are still booting
+type admissionQueue struct {
+	// waiting are the clusters waiting to be admitted, by descending
+	// priority and then by age
+	waiting []crcv1alpha1.CrcCluster
+
+	// booting is the number of other clusters currently provisioning
+	booting int
+
+	// unscheduled are admitted clusters whose VirtualMachineInstance
+	// hasn't been scheduled to a Node yet, so their requests don't
+	// show up in any Node's Pods
+	unscheduled []crcv1alpha1.CrcCluster
+}
+
+// newAdmissionQueue builds the admission queue from all CrcClusters
+// and their existing VirtualMachines and VirtualMachineInstances,
+// using our copy of crc with the freshest status
+func newAdmissionQueue(crc *crcv1alpha1.CrcCluster, crcs []crcv1alpha1.CrcCluster, vms map[types.NamespacedName]*kubevirtv1.VirtualMachine, vmis map[types.NamespacedName]*kubevirtv1.VirtualMachineInstance) *admissionQueue {
+	queue := &admissionQueue{}
+	for _, otherCrc := range crcs {
+		if otherCrc.DeletionTimestamp != nil {
+			continue
+		}
+		if otherCrc.UID == crc.UID {
+			otherCrc = *crc
+		}
+		key := types.NamespacedName{Name: otherCrc.Name, Namespace: otherCrc.Namespace}
+		vm := vms[key]
+		if !needsAdmission(&otherCrc, vm) {
+			if otherCrc.UID == crc.UID || runStrategyForCrcCluster(&otherCrc) == kubevirtv1.RunStrategyHalted {
+				continue
+			}
+			if otherCrc.Status.Phase == crcv1alpha1.CrcClusterPhaseProvisioning {
+				queue.booting++
+			}
+			if vmi := vmis[key]; vmi == nil || (vmi.Status.NodeName == "" && !vmi.IsFinal()) {
+				queue.unscheduled = append(queue.unscheduled, otherCrc)
+			}
+			continue
+		}
+		if otherCrc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeInvalidSize) ||
+			otherCrc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeQuotaExceeded) ||
+			otherCrc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypePolicyViolation) {
+			continue
+		}
+		queue.waiting = append(queue.waiting, otherCrc)
+	}
+	sort.SliceStable(queue.waiting, func(i, j int) bool {
+		a, b := &queue.waiting[i], &queue.waiting[j]
+		if a.Spec.Priority != b.Spec.Priority {
+			return a.Spec.Priority > b.Spec.Priority
+		}
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return a.CreationTimestamp.Before(&b.CreationTimestamp)
+		}
+		return a.UID < b.UID
+	})
+	return queue
+}
+
+// position returns the 1-based position of crc in the queue, or 0 if
+// it isn't waiting to be admitted
+func (q *admissionQueue) position(crc *crcv1alpha1.CrcCluster) int {
+	for i := range q.waiting {
+		if q.waiting[i].UID == crc.UID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// admit decides whether crc can be admitted, as one of a batch from
+// the front of the queue. Clusters that are admitted but not yet
+// scheduled get reserved first, and then each cluster ahead of crc as
+// if it got started first, so the batch can start together without
+// exceeding maxBooting or overcommitting a Node between them. A
+// cluster that doesn't fit holds up the ones behind it. reserve
+// counts a cluster against the Nodes' capacity, returning false if it
+// doesn't fit anywhere. admit returns why crc has to keep waiting, or
+// an empty string if it can be admitted.
+func (q *admissionQueue) admit(logger logr.Logger, crc *crcv1alpha1.CrcCluster, maxBooting int, reserve func(*crcv1alpha1.CrcCluster) (bool, error)) (string, error) {
+	for i := range q.unscheduled {
+		unscheduledCrc := &q.unscheduled[i]
+		if _, err := reserve(unscheduledCrc); err != nil {
+			logger.Info("Skipping unscheduled CrcCluster that can't be counted against Node capacity.", "CrcCluster.Namespace", unscheduledCrc.Namespace, "CrcCluster.Name", unscheduledCrc.Name, "Error", err.Error())
+		}
+	}
+
+	booting := q.booting
+	position := q.position(crc)
+	for i := 0; i < position; i++ {
+		queuedCrc := &q.waiting[i]
+		if maxBooting > 0 && booting >= maxBooting {
+			return fmt.Sprintf("Waiting on some of the %d clusters currently provisioning to finish", booting), nil
+		}
+		fits, err := reserve(queuedCrc)
+		if queuedCrc.UID == crc.UID {
+			if err != nil {
+				return "", err
+			}
+			if !fits {
+				return "Waiting on a Node with enough free CPU and memory", nil
+			}
+			return "", nil
+		}
+		if err != nil {
+			// This cluster can't start either way, so it doesn't
+			// hold up the ones behind it
+			logger.Info("Skipping queued CrcCluster that can't be admitted.", "CrcCluster.Namespace", queuedCrc.Namespace, "CrcCluster.Name", queuedCrc.Name, "Error", err.Error())
+			continue
+		}
+		if !fits {
+			return fmt.Sprintf("Waiting on %d clusters ahead in the queue", position-1), nil
+		}
+		booting++
+	}
+	return "", nil
+}
+
+// podNodeNameField indexes Pods by the Node they run on
+const podNodeNameField = "spec.nodeName"
+
+func podNodeName(obj runtime.Object) []string {
+	pod := obj.(*corev1.Pod)
+	if pod.Spec.NodeName == "" {
+		return nil
+	}
+	return []string{pod.Spec.NodeName}
+}
+
+// nodeCapacity keeps track of the CPU and memory requested on the
+// Nodes able to run VirtualMachines while admitting a batch of
+// clusters
+type nodeCapacity struct {
+	client    client.Client
+	nodes     []corev1.Node
+	requested map[string]corev1.ResourceList
+}
+
+func (r *ReconcileCrcCluster) newNodeCapacity() (*nodeCapacity, error) {
+	nodeList := &corev1.NodeList{}
+	if err := r.client.List(context.TODO(), nodeList, client.MatchingLabels{schedulableNodeLabel: "true"}); err != nil {
+		return nil, err
+	}
+	return &nodeCapacity{
+		client:    r.client,
+		nodes:     nodeList.Items,
+		requested: map[string]corev1.ResourceList{},
+	}, nil
+}
+
+// reserve returns true if any Node matching this cluster's placement
+// has enough unrequested CPU and memory for this cluster, and counts
+// the cluster's requests against the first such Node
+func (c *nodeCapacity) reserve(crc *crcv1alpha1.CrcCluster, bundle *crcv1alpha1.CrcBundle) (bool, error) {
+	vmResources, err := vmResourcesForCrcCluster(crc, bundle)
+	if err != nil {
+		return false, err
+	}
+	vmRequests := vmResources.Requests.DeepCopy()
+	if hugepages := hugepagesResourceName(crc); hugepages != "" {
+		// Guest memory comes out of the Node's preallocated
+		// hugepages instead of its regular memory
+		vmRequests[hugepages] = vmRequests[corev1.ResourceMemory]
+		delete(vmRequests, corev1.ResourceMemory)
+	}
+	placement, err := placementForCrcCluster(crc)
+	if err != nil {
+		return false, err
+	}
+
+	if len(c.nodes) == 0 {
+		// Without any Nodes labeled by KubeVirt we have no idea
+		// where VMs can go, so let the scheduler sort it out
+		return true, nil
+	}
+
+	for i := range c.nodes {
+		node := &c.nodes[i]
+		if node.Spec.Unschedulable || !nodeReady(node) || !nodeMatchesPlacement(node, placement) {
+			continue
+		}
+		requested, err := c.requestedOn(node.Name)
+		if err != nil {
+			return false, err
+		}
+		fits := true
+		for name, quantity := range vmRequests {
+			free := node.Status.Allocatable[name]
+			used := requested[name]
+			free.Sub(used)
+			if free.Cmp(quantity) < 0 {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			for name, quantity := range vmRequests {
+				total := requested[name]
+				total.Add(quantity)
+				requested[name] = total
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// requestedOn returns the resources requested by the Pods running on
+// a Node, plus those reserved for clusters admitted to it
+func (c *nodeCapacity) requestedOn(nodeName string) (corev1.ResourceList, error) {
+	if requested, found := c.requested[nodeName]; found {
+		return requested, nil
+	}
+	podList := &corev1.PodList{}
+	if err := c.client.List(context.TODO(), podList, client.MatchingFields{podNodeNameField: nodeName}); err != nil {
+		return nil, err
+	}
+	requested := corev1.ResourceList{}
+	for _, pod := range podList.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for name, quantity := range podRequests(&pod) {
+			total := requested[name]
+			total.Add(quantity)
+			requested[name] = total
+		}
+	}
+	c.requested[nodeName] = requested
+	return requested, nil
+}
+
+// podRequests returns the resources requested by a pod, which is the
+// larger of the sum of its containers and its largest init container
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		for name, quantity := range container.Resources.Requests {
+			total := requests[name]
+			total.Add(quantity)
+			requests[name] = total
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if quantity.Cmp(requests[name]) > 0 {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	return requests
+}
+
+func nodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// phaseForCrcCluster returns the phase of a cluster based on its
+// status conditions
+func phaseForCrcCluster(crc *crcv1alpha1.CrcCluster) crcv1alpha1.CrcClusterPhase {
+	switch {
+	case crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeInvalidSize),
+		crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypePolicyViolation),
+		crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeQuotaExceeded):
+		return crcv1alpha1.CrcClusterPhasePending
+	case crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeQueued):
+		return crcv1alpha1.CrcClusterPhaseQueued
+	case crc.Status.Stopped:
+		return crcv1alpha1.CrcClusterPhaseStopped
+	case crc.Status.PowerState == crcv1alpha1.CrcClusterPowerStatePaused:
+		return crcv1alpha1.CrcClusterPhasePaused
+	case crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeReady):
+		return crcv1alpha1.CrcClusterPhaseReady
+	default:
+		return crcv1alpha1.CrcClusterPhaseProvisioning
+	}
+}
@@ -0,0 +1,215 @@
+package crccluster
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+)
+
+var testCreationTime = time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+func newTestCrc(name string, priority int, age time.Duration) crcv1alpha1.CrcCluster {
+	return crcv1alpha1.CrcCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "crc",
+			UID:               types.UID(name),
+			CreationTimestamp: metav1.NewTime(testCreationTime.Add(-age)),
+		},
+		Spec: crcv1alpha1.CrcClusterSpec{
+			CPU:      4,
+			Memory:   "16Gi",
+			Priority: priority,
+		},
+	}
+}
+
+func newTestVM(crc *crcv1alpha1.CrcCluster, runStrategy kubevirtv1.VirtualMachineRunStrategy) *kubevirtv1.VirtualMachine {
+	return &kubevirtv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: crc.Name, Namespace: crc.Namespace},
+		Spec:       kubevirtv1.VirtualMachineSpec{RunStrategy: &runStrategy},
+	}
+}
+
+func newTestNode(name string, cpu string, memory string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
+
+func crcNames(crcs []crcv1alpha1.CrcCluster) string {
+	names := []string{}
+	for _, crc := range crcs {
+		names = append(names, crc.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestNewAdmissionQueue(t *testing.T) {
+	old := newTestCrc("old", 0, 3*time.Hour)
+	young := newTestCrc("young", 0, time.Hour)
+	important := newTestCrc("important", 10, 0)
+	overQuota := newTestCrc("over-quota", 0, 4*time.Hour)
+	overQuota.SetConditionBool(crcv1alpha1.ConditionTypeQuotaExceeded, true)
+	pending := newTestCrc("pending", 0, 5*time.Hour)
+	pending.Status.Phase = crcv1alpha1.CrcClusterPhaseProvisioning
+	scheduled := newTestCrc("scheduled", 0, 6*time.Hour)
+	scheduled.Status.Phase = crcv1alpha1.CrcClusterPhaseProvisioning
+	ready := newTestCrc("ready", 0, 7*time.Hour)
+	ready.Status.Phase = crcv1alpha1.CrcClusterPhaseReady
+	stopped := newTestCrc("stopped", 0, 8*time.Hour)
+	stopped.Spec.Stopped = true
+
+	vms := map[types.NamespacedName]*kubevirtv1.VirtualMachine{}
+	vmis := map[types.NamespacedName]*kubevirtv1.VirtualMachineInstance{}
+	for _, crc := range []*crcv1alpha1.CrcCluster{&pending, &scheduled, &ready, &stopped} {
+		runStrategy := runStrategyForCrcCluster(crc)
+		vms[types.NamespacedName{Name: crc.Name, Namespace: crc.Namespace}] = newTestVM(crc, runStrategy)
+	}
+	for _, crc := range []*crcv1alpha1.CrcCluster{&scheduled, &ready} {
+		vmis[types.NamespacedName{Name: crc.Name, Namespace: crc.Namespace}] = &kubevirtv1.VirtualMachineInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: crc.Name, Namespace: crc.Namespace},
+			Status:     kubevirtv1.VirtualMachineInstanceStatus{NodeName: "node-1"},
+		}
+	}
+
+	crcs := []crcv1alpha1.CrcCluster{young, overQuota, old, pending, scheduled, ready, stopped, important}
+	queue := newAdmissionQueue(&young, crcs, vms, vmis)
+
+	if names := crcNames(queue.waiting); names != "important,old,young" {
+		t.Errorf("Expected waiting clusters important,old,young, got %s", names)
+	}
+	if queue.booting != 2 {
+		t.Errorf("Expected 2 booting clusters, got %d", queue.booting)
+	}
+	if names := crcNames(queue.unscheduled); names != "pending" {
+		t.Errorf("Expected unscheduled cluster pending, got %s", names)
+	}
+	if position := queue.position(&young); position != 3 {
+		t.Errorf("Expected young at position 3, got %d", position)
+	}
+	if position := queue.position(&ready); position != 0 {
+		t.Errorf("Expected ready not to be queued, got position %d", position)
+	}
+}
+
+func TestAdmissionQueueAdmit(t *testing.T) {
+	first := newTestCrc("first", 0, 3*time.Hour)
+	second := newTestCrc("second", 0, 2*time.Hour)
+	third := newTestCrc("third", 0, time.Hour)
+	unscheduled := newTestCrc("unscheduled", 0, 4*time.Hour)
+
+	tests := []struct {
+		name        string
+		crc         *crcv1alpha1.CrcCluster
+		booting     int
+		maxBooting  int
+		slots       int
+		unscheduled []crcv1alpha1.CrcCluster
+		reason      string
+	}{
+		{name: "front of the queue fits", crc: &first, slots: 1},
+		{name: "batch fits together", crc: &third, slots: 3},
+		{name: "doesn't fit itself", crc: &second, slots: 1, reason: "Waiting on a Node with enough free CPU and memory"},
+		{name: "cluster ahead doesn't fit", crc: &third, slots: 1, reason: "Waiting on 2 clusters ahead in the queue"},
+		{name: "batch limited by booting clusters", crc: &third, booting: 1, maxBooting: 3, slots: 3, reason: "Waiting on some of the 3 clusters currently provisioning to finish"},
+		{name: "batch within booting clusters", crc: &second, booting: 1, maxBooting: 3, slots: 3},
+		{name: "unscheduled clusters reserved first", crc: &first, slots: 1, unscheduled: []crcv1alpha1.CrcCluster{unscheduled}, reason: "Waiting on a Node with enough free CPU and memory"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			queue := &admissionQueue{
+				waiting:     []crcv1alpha1.CrcCluster{first, second, third},
+				booting:     test.booting,
+				unscheduled: test.unscheduled,
+			}
+			slots := test.slots
+			reason, err := queue.admit(log, test.crc, test.maxBooting, func(crc *crcv1alpha1.CrcCluster) (bool, error) {
+				if slots == 0 {
+					return false, nil
+				}
+				slots--
+				return true, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reason != test.reason {
+				t.Errorf("Expected reason %q, got %q", test.reason, reason)
+			}
+		})
+	}
+}
+
+func TestNodeCapacityReserve(t *testing.T) {
+	crc := newTestCrc("crc", 0, 0)
+	bundle := &crcv1alpha1.CrcBundle{}
+
+	cordoned := newTestNode("cordoned", "16", "64Gi")
+	cordoned.Spec.Unschedulable = true
+	capacity := &nodeCapacity{
+		nodes: []corev1.Node{
+			cordoned,
+			newTestNode("small", "16", "24Gi"),
+			newTestNode("large", "16", "36Gi"),
+		},
+		requested: map[string]corev1.ResourceList{
+			"small": {corev1.ResourceMemory: resource.MustParse("16Gi")},
+			"large": {corev1.ResourceMemory: resource.MustParse("8Gi")},
+		},
+	}
+
+	// Only the large Node has room for 16Gi, and only once
+	for i, expected := range []bool{true, false} {
+		fits, err := capacity.reserve(&crc, bundle)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fits != expected {
+			t.Errorf("Reservation %d: expected fits %v, got %v", i+1, expected, fits)
+		}
+	}
+	large := capacity.requested["large"]
+	if memory := large[corev1.ResourceMemory]; memory.Cmp(resource.MustParse("24Gi")) != 0 {
+		t.Errorf("Expected 24Gi of memory requested on the large Node, got %s", memory.String())
+	}
+	if _, found := capacity.requested["cordoned"]; found {
+		t.Errorf("Expected nothing reserved on the cordoned Node")
+	}
+
+	// Placement rules out Nodes without the right labels
+	crc.Spec.Placement.NodeSelector = map[string]string{"crc": "true"}
+	fits, err := capacity.reserve(&crc, bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fits {
+		t.Errorf("Expected no Node matching the placement")
+	}
+
+	// Without any Nodes labeled by KubeVirt the scheduler decides
+	empty := &nodeCapacity{requested: map[string]corev1.ResourceList{}}
+	fits, err = empty.reserve(&crc, bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fits {
+		t.Errorf("Expected clusters to fit without any known Nodes")
+	}
+}
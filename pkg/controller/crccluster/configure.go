@@ -0,0 +1,133 @@
+package crccluster
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	sshClient "github.com/code-ready/machine/libmachine/ssh"
+	"github.com/go-logr/logr"
+	configv1 "github.com/openshift/api/config/v1"
+	configv1Client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	"golang.org/x/crypto/bcrypt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Configuration steps that persist on a cluster's disk between
+// restarts
+const (
+	configStepAdminUser  string = "AdminUser"
+	configStepPullSecret string = "PullSecret"
+	configStepClusterID  string = "ClusterID"
+)
+
+func hasConfigStep(crc *crcv1alpha1.CrcCluster, step string) bool {
+	for _, configuredStep := range crc.Status.ConfiguredSteps {
+		if configuredStep == step {
+			return true
+		}
+	}
+	return false
+}
+
+func setConfigStep(crc *crcv1alpha1.CrcCluster, step string) {
+	if !hasConfigStep(crc, step) {
+		crc.Status.ConfiguredSteps = append(crc.Status.ConfiguredSteps, step)
+	}
+}
+
+// configureStep runs a configuration step unless it was already done
+// and verify confirms it's still in place
+func configureStep(logger logr.Logger, crc *crcv1alpha1.CrcCluster, step string, verify func() (bool, error), configure func() error) error {
+	if hasConfigStep(crc, step) {
+		valid, err := verify()
+		if err != nil {
+			return err
+		}
+		if valid {
+			logger.Info("Verified configuration step is still in place.", "Step", step)
+			return nil
+		}
+		logger.Info("Configuration step is no longer in place, redoing it.", "Step", step)
+	}
+	if err := configure(); err != nil {
+		return err
+	}
+	setConfigStep(crc, step)
+	return nil
+}
+
+// verifyClusterAdminUser checks that the cluster's htpasswd secret
+// still matches the kubeadmin password and the kubeadmin user is
+// still a cluster admin. Comparing the hash avoids rewriting the
+// secret, which redeploys the OAuth server.
+func (r *ReconcileCrcCluster) verifyClusterAdminUser(crc *crcv1alpha1.CrcCluster, k8sClient *kubernetes.Clientset) (bool, error) {
+	secret, err := k8sClient.CoreV1().Secrets("openshift-config").Get("htpass-secret", metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	htpasswd := strings.TrimSpace(string(secret.Data["htpasswd"]))
+	if !strings.HasPrefix(htpasswd, "kubeadmin:") {
+		return false, nil
+	}
+	passwordHash := strings.TrimPrefix(htpasswd, "kubeadmin:")
+	if bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(crc.Status.KubeAdminPassword)) != nil {
+		return false, nil
+	}
+
+	_, err = k8sClient.RbacV1().ClusterRoleBindings().Get("crc-cluster-admin", metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// verifyPullSecret checks that the pull secret on the Node and in the
+// cluster still match the cluster's pull secret
+func (r *ReconcileCrcCluster) verifyPullSecret(crc *crcv1alpha1.CrcCluster, sshClient *sshClient.NativeClient, k8sClient *kubernetes.Clientset) (bool, error) {
+	crcPullSecretBytes, err := base64.StdEncoding.DecodeString(crc.Spec.PullSecret)
+	if err != nil {
+		return false, err
+	}
+
+	output, err := sshQuickOutput(sshClient, "sudo sha256sum /var/lib/kubelet/config.json")
+	if err != nil {
+		// Most likely the file is missing
+		return false, nil
+	}
+	expectedSum := fmt.Sprintf("%x", sha256.Sum256(crcPullSecretBytes))
+	if !strings.HasPrefix(strings.TrimSpace(output), expectedSum) {
+		return false, nil
+	}
+
+	secret, err := k8sClient.CoreV1().Secrets("openshift-config").Get("pull-secret", metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(secret.Data[".dockerconfigjson"], crcPullSecretBytes), nil
+}
+
+// verifyClusterID checks that the cluster still has its unique ID
+func (r *ReconcileCrcCluster) verifyClusterID(crc *crcv1alpha1.CrcCluster, restConfig *rest.Config) (bool, error) {
+	if crc.Status.ClusterID == "" {
+		return false, nil
+	}
+	configClient, err := configv1Client.NewForConfig(restConfig)
+	if err != nil {
+		return false, err
+	}
+	clusterVersion, err := configClient.ClusterVersions().Get("version", metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	return clusterVersion.Spec.ClusterID == configv1.ClusterID(crc.Status.ClusterID), nil
+}
@@ -22,6 +22,8 @@ import (
 	"time"
 
 	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/bbrowning/crc-operator/pkg/bundle"
+	"github.com/bbrowning/crc-operator/pkg/size"
 	libMachineLog "github.com/code-ready/machine/libmachine/log"
 	"github.com/code-ready/machine/libmachine/mcnutils"
 	sshClient "github.com/code-ready/machine/libmachine/ssh"
@@ -51,11 +53,13 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 	kubevirtv1 "kubevirt.io/client-go/api/v1"
 	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -70,6 +74,11 @@ var defaultBundleName = os.Getenv("DEFAULT_BUNDLE_NAME")
 var routesHelperImage = os.Getenv("ROUTES_HELPER_IMAGE")
 var bundleNs = os.Getenv("POD_NAMESPACE")
 
+// AdmissionWebhooksEnabled is set when the operator serves its
+// admission webhooks, which check access to anything a CrcCluster
+// gets cloned from before it gets stored
+var AdmissionWebhooksEnabled = false
+
 const (
 	sshPort       int    = 2022
 	apiServerPort int    = 6443
@@ -101,6 +110,8 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 		client:         mgr.GetClient(),
 		scheme:         mgr.GetScheme(),
 		routeAPIExists: routeAPIExists(mgr),
+		recorder:       mgr.GetEventRecorderFor("crccluster-controller"),
+		restClient:     kubernetes.NewForConfigOrDie(mgr.GetConfig()).CoreV1().RESTClient(),
 	}
 }
 
@@ -132,12 +143,55 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Index Pods by the Node they run on, so checking the free
+	// capacity of a Node only lists its own Pods
+	err = mgr.GetFieldIndexer().IndexField(&corev1.Pod{}, podNodeNameField, podNodeName)
+	if err != nil {
+		return err
+	}
+
 	// Watch for changes to primary resource CrcCluster
 	err = c.Watch(&source.Kind{Type: &crcv1alpha1.CrcCluster{}}, &handler.EnqueueRequestForObject{}, predicate.GenerationChangedPredicate{})
 	if err != nil {
 		return err
 	}
 
+	// Also watch for CrcClusters getting claimed from a pool or
+	// stopped for a snapshot or export, which only changes their
+	// labels or annotations
+	err = c.Watch(&source.Kind{Type: &crcv1alpha1.CrcCluster{}}, &handler.EnqueueRequestForObject{}, predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return false },
+		DeleteFunc: func(e event.DeleteEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.MetaOld.GetLabels()[crcv1alpha1.ClaimLabel] != e.MetaNew.GetLabels()[crcv1alpha1.ClaimLabel] ||
+				e.MetaOld.GetAnnotations()[crcv1alpha1.SnapshotAnnotation] != e.MetaNew.GetAnnotations()[crcv1alpha1.SnapshotAnnotation] ||
+				e.MetaOld.GetAnnotations()[crcv1alpha1.ExportAnnotation] != e.MetaNew.GetAnnotations()[crcv1alpha1.ExportAnnotation]
+		},
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for CrcClusterExports getting deleted so the CrcCluster
+	// they kept stopped starts again right away
+	err = c.Watch(&source.Kind{Type: &crcv1alpha1.CrcClusterExport{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(o handler.MapObject) []reconcile.Request {
+			export := o.Object.(*crcv1alpha1.CrcClusterExport)
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: export.Spec.ClusterName, Namespace: export.Namespace}},
+			}
+		}),
+	}, predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return false },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return true },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	})
+	if err != nil {
+		return err
+	}
+
 	// Watch for changes to secondary resource VirtualMachines and requeue the owner CrcCluster
 	err = c.Watch(&source.Kind{Type: &kubevirtv1.VirtualMachine{}}, &handler.EnqueueRequestForOwner{
 		IsController: true,
@@ -147,6 +201,84 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for changes to the VirtualMachineInstances of our
+	// VirtualMachines, which are owned by the VirtualMachine instead
+	// of the CrcCluster but share its name
+	err = c.Watch(&source.Kind{Type: &kubevirtv1.VirtualMachineInstance{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(o handler.MapObject) []reconcile.Request {
+			if o.Meta.GetLabels()["crcCluster"] == "" {
+				return nil
+			}
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: o.Meta.GetLabels()["crcCluster"], Namespace: o.Meta.GetNamespace()}},
+			}
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to the DataVolumes of our VirtualMachines,
+	// which are owned by the VirtualMachine instead of the CrcCluster
+	// but share its name
+	err = c.Watch(&source.Kind{Type: &cdiv1.DataVolume{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(o handler.MapObject) []reconcile.Request {
+			owner := metav1.GetControllerOf(o.Meta)
+			if owner == nil || owner.Kind != "VirtualMachine" {
+				return nil
+			}
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: owner.Name, Namespace: o.Meta.GetNamespace()}},
+			}
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to secondary resource
+	// VirtualMachineInstanceMigration and requeue the owner CrcCluster
+	err = c.Watch(&source.Kind{Type: &kubevirtv1.VirtualMachineInstanceMigration{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &crcv1alpha1.CrcCluster{},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for Nodes getting cordoned or uncordoned, which happens
+	// when they're drained, and requeue the CrcClusters running on
+	// them
+	nodeClient := mgr.GetClient()
+	err = c.Watch(&source.Kind{Type: &corev1.Node{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(o handler.MapObject) []reconcile.Request {
+			vmiList := &kubevirtv1.VirtualMachineInstanceList{}
+			if err := nodeClient.List(context.TODO(), vmiList, client.HasLabels{"crcCluster"}); err != nil {
+				log.Error(err, "Failed to list VirtualMachineInstances.")
+				return nil
+			}
+			requests := []reconcile.Request{}
+			for _, vmi := range vmiList.Items {
+				if vmi.Status.NodeName == o.Meta.GetName() {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{Name: vmi.Labels["crcCluster"], Namespace: vmi.Namespace},
+					})
+				}
+			}
+			return requests
+		}),
+	}, predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return false },
+		DeleteFunc: func(e event.DeleteEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.(*corev1.Node).Spec.Unschedulable != e.ObjectNew.(*corev1.Node).Spec.Unschedulable
+		},
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	})
+	if err != nil {
+		return err
+	}
+
 	// Watch for changes to secondary resource Kubernetes Service and
 	// requeue the owner CrcCluster
 	err = c.Watch(&source.Kind{Type: &corev1.Service{}}, &handler.EnqueueRequestForOwner{
@@ -204,6 +336,12 @@ type ReconcileCrcCluster struct {
 
 	// Whether this cluster has OpenShift Routes
 	routeAPIExists bool
+
+	recorder record.EventRecorder
+
+	// restClient calls APIs the client can't, like KubeVirt's
+	// subresources
+	restClient rest.Interface
 }
 
 // Reconcile reads that state of the cluster for a CrcCluster object and makes changes based on the state read
@@ -238,6 +376,65 @@ func (r *ReconcileCrcCluster) Reconcile(request reconcile.Request) (reconcile.Re
 		crc, err = r.initializeStatusConditions(reqLogger, crc)
 	}
 
+	// Fill in the CPU and memory of the cluster's size, in case the
+	// admission webhook didn't
+	if crc.Spec.CPU == 0 || crc.Spec.Memory == "" {
+		if _, err := size.Apply(&crc.Spec); err != nil {
+			if size.IsUnknownSize(err) {
+				// Nothing to do until the size gets fixed, which
+				// changes the spec and reconciles again
+				reqLogger.Info("CrcCluster asks for an unknown size.", "Size", crc.Spec.Size)
+				if !crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeInvalidSize) {
+					r.recorder.Event(crc, corev1.EventTypeWarning, "InvalidSize", err.Error())
+				}
+				crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeInvalidSize, true, "UnknownSize", err.Error())
+				crc, err = r.updateCrcClusterStatus(crc)
+				return reconcile.Result{}, err
+			}
+			reqLogger.Error(err, "Failed to apply size to CrcCluster.", "Size", crc.Spec.Size)
+			return reconcile.Result{}, err
+		}
+		reqLogger.Info("Updating CrcCluster CPU and memory from its size.", "Size", crc.Spec.Size)
+		err = r.client.Update(context.TODO(), crc)
+		return reconcile.Result{}, err
+	}
+	crc.SetConditionBool(crcv1alpha1.ConditionTypeInvalidSize, false)
+
+	crc, err = r.acceptClaim(reqLogger, crc)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	released, err := r.releaseDeletedSnapshot(reqLogger, crc)
+	if err != nil || released {
+		return reconcile.Result{}, err
+	}
+	released, err = r.releaseDeletedExport(reqLogger, crc)
+	if err != nil || released {
+		return reconcile.Result{}, err
+	}
+
+	crc, sourceReady, err := r.prepareSource(reqLogger, crc)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !sourceReady {
+		return reconcile.Result{RequeueAfter: time.Second * 10}, nil
+	}
+
+	crc, expired, err := r.enforceMaxLifetime(reqLogger, crc)
+	if err != nil || expired {
+		return reconcile.Result{}, err
+	}
+
+	crc, resetting, err := r.resetCluster(reqLogger, crc)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if resetting {
+		return reconcile.Result{RequeueAfter: time.Second * 5}, nil
+	}
+
 	bundle, err := r.bundleForCrc(crc)
 	if err != nil {
 		// TODO: This is a permanent error and thus should just fail
@@ -247,6 +444,44 @@ func (r *ReconcileCrcCluster) Reconcile(request reconcile.Request) (reconcile.Re
 	}
 	reqLogger.Info("Located bundle for cluster", "Bundle.Name", bundle.Name, "Bundle.Spec.Image", bundle.Spec.Image)
 
+	existingVirtualMachine, err := r.existingVirtualMachine(reqLogger, crc)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	crc, admitted, err := r.enforcePolicy(reqLogger, crc, existingVirtualMachine)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !admitted {
+		// Check again later in case the policy changes
+		return reconcile.Result{RequeueAfter: time.Minute}, nil
+	}
+
+	crc, admitted, err = r.enforceQuota(reqLogger, crc, existingVirtualMachine)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !admitted {
+		return reconcile.Result{RequeueAfter: time.Second * 30}, nil
+	}
+
+	crc, admitted, err = r.admitByCapacity(reqLogger, crc, bundle)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !admitted {
+		return reconcile.Result{RequeueAfter: time.Second * 15}, nil
+	}
+
+	crc, halt, err := r.shutdownGracefully(reqLogger, crc, bundle)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !halt {
+		return reconcile.Result{RequeueAfter: time.Second * 5}, nil
+	}
+
 	virtualMachine, err := r.ensureVirtualMachineExists(reqLogger, crc, bundle)
 	if err != nil {
 		return reconcile.Result{}, err
@@ -257,6 +492,10 @@ func (r *ReconcileCrcCluster) Reconcile(request reconcile.Request) (reconcile.Re
 		return reconcile.Result{}, err
 	}
 
+	if err := r.ensurePodDisruptionBudgetExists(reqLogger, crc); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	apiHost := ""
 	if r.routeAPIExists {
 		route, err := r.ensureAPIRouteExists(reqLogger, crc)
@@ -275,11 +514,34 @@ func (r *ReconcileCrcCluster) Reconcile(request reconcile.Request) (reconcile.Re
 	crc.Status.BaseDomain = strings.Replace(apiHost, "api.", "", 1)
 	crc.Status.ConsoleURL = fmt.Sprintf("https://%s", routeHostForDomain(crc.Status.BaseDomain, "console", "openshift-console"))
 
-	r.updateVirtualMachineNotReadyCondition(virtualMachine, crc)
-	if virtualMachine.Spec.Running != nil && !*virtualMachine.Spec.Running {
-		crc.Status.Stopped = true
-	} else {
-		crc.Status.Stopped = false
+	vmRequests := virtualMachine.Spec.Template.Spec.Domain.Resources.Requests
+	requestedCPU := vmRequests[corev1.ResourceCPU]
+	requestedMemory := vmRequests[corev1.ResourceMemory]
+	crc.Status.RequestedCPU = requestedCPU.String()
+	crc.Status.RequestedMemory = requestedMemory.String()
+
+	if err := r.updateDataVolumeStatus(reqLogger, crc, virtualMachine); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	paused, err := r.ensurePauseState(reqLogger, crc)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	// A paused VirtualMachine, or one just unpaused, may not be
+	// Ready, but it comes back exactly as it was so there's no need
+	// to start kubelet or configure the cluster again
+	if !paused && crc.Status.PowerState != crcv1alpha1.CrcClusterPowerStatePaused {
+		r.updateVirtualMachineNotReadyCondition(virtualMachine, crc)
+	}
+	if err := r.updatePowerState(virtualMachine, crc); err != nil {
+		reqLogger.Error(err, "Failed to get VirtualMachineInstance.")
+		return reconcile.Result{}, err
+	}
+
+	crc, drained, err := r.handleNodeDrain(reqLogger, crc)
+	if err != nil {
+		return reconcile.Result{}, err
 	}
 
 	r.updateNetworkingNotReadyCondition(k8sService, crc)
@@ -293,6 +555,26 @@ func (r *ReconcileCrcCluster) Reconcile(request reconcile.Request) (reconcile.Re
 		return reconcile.Result{}, err
 	}
 
+	if drained {
+		return reconcile.Result{RequeueAfter: time.Second * 10}, nil
+	}
+
+	if paused {
+		reqLogger.Info("Cluster is paused - skipping reconciliation")
+		return requeueForExpiration(crc), nil
+	}
+
+	if !crc.Status.Stopped {
+		crc, err = r.resize(reqLogger, crc, virtualMachine)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		crc, err = r.updateCrcClusterStatus(crc)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
 	if crc.Status.Stopped {
 		reqLogger.Info("Deleting route helper pod for stopped cluster.")
 		if err := r.deleteRouteHelperPod(crc); err != nil {
@@ -306,7 +588,7 @@ func (r *ReconcileCrcCluster) Reconcile(request reconcile.Request) (reconcile.Re
 		if crc.Status.Stopped {
 			// The VM is not ready but the cluster is stopped, so we're good
 			reqLogger.Info("Cluster is stopped and virtual machine is not ready - this is expected")
-			return reconcile.Result{}, nil
+			return requeueForExpiration(crc), nil
 		}
 		reqLogger.Info("Waiting on the VirtualMachine to become Ready before continuing")
 		return reconcile.Result{RequeueAfter: time.Second * 10}, nil
@@ -355,6 +637,20 @@ func (r *ReconcileCrcCluster) Reconcile(request reconcile.Request) (reconcile.Re
 	}
 
 	if crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeKubeletNotReady) {
+		crc, err = r.prepareCertificateRecovery(reqLogger, clusterSSHClient, crc)
+		if err != nil {
+			reqLogger.Error(err, "Failed to check for expired certificates.")
+			return reconcile.Result{}, err
+		}
+
+		if err := r.relocateVarToScratchDisk(reqLogger, clusterSSHClient, virtualMachine); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		if err := r.mountAdditionalDisks(reqLogger, clusterSSHClient, crc); err != nil {
+			return reconcile.Result{}, err
+		}
+
 		crc, err = r.ensureKubeletStarted(reqLogger, clusterSSHClient, crc, bundle)
 		if err != nil {
 			reqLogger.Error(err, "Failed to start Kubelet.")
@@ -362,6 +658,19 @@ func (r *ReconcileCrcCluster) Reconcile(request reconcile.Request) (reconcile.Re
 		}
 	}
 
+	crc, err = r.finishResize(reqLogger, clusterSSHClient, crc)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	crc, restored, err := r.restoreEtcd(reqLogger, crc, clusterSSHClient)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if restored {
+		return reconcile.Result{RequeueAfter: time.Second * 10}, nil
+	}
+
 	crcK8sConfig, err := restConfigFromCrcCluster(crc, bundle)
 	if err != nil {
 		reqLogger.Error(err, "Error generating Kubernetes REST config from kubeconfig.")
@@ -387,25 +696,59 @@ func (r *ReconcileCrcCluster) Reconcile(request reconcile.Request) (reconcile.Re
 		return reconcile.Result{RequeueAfter: time.Second * 10}, nil
 	}
 
+	reqLogger.Info("Approving CSRs.")
+	if err := r.approveCSRs(reqLogger, crc, insecureK8sClient); err != nil {
+		reqLogger.Error(err, "Error approving CSRs.")
+		return reconcile.Result{}, err
+	}
+
+	crc, recovered, err := r.recoverCertificates(reqLogger, clusterHost, crc, insecureK8sClient)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !recovered {
+		reqLogger.Info("Waiting on the cluster to recover from expired certificates.")
+		return reconcile.Result{RequeueAfter: time.Second * 10}, nil
+	}
+
 	if crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeClusterNotConfigured) {
 		reqLogger.Info("Updating cluster admin password.")
-		if err := r.updateClusterAdminUser(crc, insecureK8sClient); err != nil {
+		err = configureStep(reqLogger, crc, configStepAdminUser, func() (bool, error) {
+			return r.verifyClusterAdminUser(crc, insecureK8sClient)
+		}, func() error {
+			return r.updateClusterAdminUser(crc, insecureK8sClient)
+		})
+		if err != nil {
 			reqLogger.Error(err, "Error updating cluster admin password.")
 			return reconcile.Result{}, err
 		}
 
 		reqLogger.Info("Updating pull secret.")
-		if err := r.updatePullSecret(crc, clusterSSHClient, insecureK8sClient); err != nil {
+		err = configureStep(reqLogger, crc, configStepPullSecret, func() (bool, error) {
+			return r.verifyPullSecret(crc, clusterSSHClient, insecureK8sClient)
+		}, func() error {
+			return r.updatePullSecret(crc, clusterSSHClient, insecureK8sClient)
+		})
+		if err != nil {
 			reqLogger.Error(err, "Error updating pull secret.")
 			return reconcile.Result{}, err
 		}
 
 		reqLogger.Info("Updating cluster ID.")
-		crc, err = r.updateClusterID(crc, insecureCrcK8sConfig)
+		err = configureStep(reqLogger, crc, configStepClusterID, func() (bool, error) {
+			return r.verifyClusterID(crc, insecureCrcK8sConfig)
+		}, func() error {
+			crc, err = r.updateClusterID(crc, insecureCrcK8sConfig)
+			return err
+		})
 		if err != nil {
 			reqLogger.Error(err, "Error updating cluster ID.")
 			return reconcile.Result{}, err
 		}
+		crc, err = r.updateCrcClusterStatus(crc)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
 
 		reqLogger.Info("Updating cluster admin client certificate.")
 		crc, err = r.updateClusterAdminCert(crc, insecureK8sClient)
@@ -420,9 +763,9 @@ func (r *ReconcileCrcCluster) Reconcile(request reconcile.Request) (reconcile.Re
 			return reconcile.Result{}, err
 		}
 
-		reqLogger.Info("Approving CSRs.")
-		if err := r.approveCSRs(insecureK8sClient); err != nil {
-			reqLogger.Error(err, "Error approving CSRs.")
+		reqLogger.Info("Uncordoning nodes.")
+		if err := setNodesUnschedulable(insecureK8sClient, false); err != nil {
+			reqLogger.Error(err, "Error uncordoning nodes.")
 			return reconcile.Result{}, err
 		}
 
@@ -439,6 +782,21 @@ func (r *ReconcileCrcCluster) Reconcile(request reconcile.Request) (reconcile.Re
 		}
 	}
 
+	if crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeCredentialsNotRotated) {
+		reqLogger.Info("Rotating credentials of claimed cluster.")
+		crc, err = r.rotateCredentials(crc, clusterSSHClient, insecureK8sClient)
+		if err != nil {
+			reqLogger.Error(err, "Error rotating credentials of claimed cluster.")
+			return reconcile.Result{}, err
+		}
+	}
+
+	reqLogger.Info("Ensuring local storage for additional disks.")
+	if err := r.ensureLocalStorage(reqLogger, crc, insecureK8sClient); err != nil {
+		reqLogger.Error(err, "Error ensuring local storage for additional disks.")
+		return reconcile.Result{}, err
+	}
+
 	reqLogger.Info("Ensuring ingress controllers updated.")
 	if err := r.ensureIngressControllersUpdated(crc, insecureCrcK8sConfig); err != nil {
 		reqLogger.Error(err, "Error updating ingress controllers.")
@@ -544,7 +902,28 @@ func (r *ReconcileCrcCluster) Reconcile(request reconcile.Request) (reconcile.Re
 
 	fmt.Printf("blah blah k8sClient %v\n", k8sClient)
 
-	return reconcile.Result{}, nil
+	crc, err = r.backupEtcd(reqLogger, crc, clusterSSHClient)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Keep checking for kubelet certificate renewals
+	interval, err := csrApprovalIntervalForCrcCluster()
+	if err != nil {
+		reqLogger.Error(err, "Invalid CSR approval interval.")
+		return reconcile.Result{}, err
+	}
+	if resizeAfter := requeueForResize(crc); resizeAfter > 0 && resizeAfter < interval {
+		interval = resizeAfter
+	}
+	if backupAfter := requeueForEtcdBackup(crc); backupAfter > 0 && backupAfter < interval {
+		interval = backupAfter
+	}
+	result := requeueForExpiration(crc)
+	if result.RequeueAfter == 0 || result.RequeueAfter > interval {
+		result.RequeueAfter = interval
+	}
+	return result, nil
 }
 
 func (r *ReconcileCrcCluster) waitForConsoleURL(crc *crcv1alpha1.CrcCluster) (bool, error) {
@@ -815,6 +1194,7 @@ func (r *ReconcileCrcCluster) updateCrcClusterStatus(crc *crcv1alpha1.CrcCluster
 	if err != nil {
 		return crc, err
 	}
+	crc.Status.Phase = phaseForCrcCluster(crc)
 	if !reflect.DeepEqual(crc.Status, existingCrc.Status) {
 		err := r.client.Status().Update(context.TODO(), crc)
 		if err != nil {
@@ -1000,7 +1380,7 @@ func (r *ReconcileCrcCluster) waitForOpenShiftAPIServer(k8sClient *kubernetes.Cl
 		return false, err
 	}
 	if len(pods.Items) < 1 {
-		return false, fmt.Errorf("Expected at least one OpenShift API server pod, found %d", pods.Items)
+		return false, fmt.Errorf("Expected at least one OpenShift API server pod, found %d", len(pods.Items))
 	}
 	for _, pod := range pods.Items {
 		for _, condition := range pod.Status.Conditions {
@@ -1111,8 +1491,10 @@ func (r *ReconcileCrcCluster) updateClusterAdminCert(crc *crcv1alpha1.CrcCluster
 		return crc, err
 	}
 
-	if err := r.approveCSR(existingCsr, k8sClient); err != nil {
-		return crc, err
+	if !csrDecided(existingCsr) {
+		if err := approveCSR(existingCsr, k8sClient); err != nil {
+			return crc, err
+		}
 	}
 
 	approvedCsr, err := k8sClient.CertificatesV1beta1().CertificateSigningRequests().Get(csr.Name, metav1.GetOptions{})
@@ -1164,41 +1546,6 @@ func (r *ReconcileCrcCluster) removeSharedKubeadminSecret(k8sClient *kubernetes.
 	return err
 }
 
-func (r *ReconcileCrcCluster) approveCSRs(k8sClient *kubernetes.Clientset) error {
-	csrs, err := k8sClient.CertificatesV1beta1().CertificateSigningRequests().List(metav1.ListOptions{})
-	if err != nil {
-		return err
-	}
-	for _, csr := range csrs.Items {
-		if err := r.approveCSR(&csr, k8sClient); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func (r *ReconcileCrcCluster) approveCSR(csr *certificatesv1beta1.CertificateSigningRequest, k8sClient *kubernetes.Clientset) error {
-	var alreadyApproved bool
-	for _, condition := range csr.Status.Conditions {
-		if condition.Type == certificatesv1beta1.CertificateApproved {
-			alreadyApproved = true
-		}
-	}
-	if !alreadyApproved {
-		csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1beta1.CertificateSigningRequestCondition{
-			Type:           certificatesv1beta1.CertificateApproved,
-			Reason:         "CRCApprove",
-			Message:        "This CSR was approved by CodeReady Containers operator.",
-			LastUpdateTime: metav1.Now(),
-		})
-		_, err := k8sClient.CertificatesV1beta1().CertificateSigningRequests().UpdateApproval(csr)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 func (r *ReconcileCrcCluster) updateIngressDomain(crc *crcv1alpha1.CrcCluster, restConfig *rest.Config) error {
 	configClient, err := configv1Client.NewForConfig(restConfig)
 	if err != nil {
@@ -1589,7 +1936,59 @@ func (r *ReconcileCrcCluster) initializeStatusConditions(logger logr.Logger, crc
 			Type:   crcv1alpha1.ConditionTypeReady,
 			Status: corev1.ConditionFalse,
 		},
+		status.Condition{
+			Type:   crcv1alpha1.ConditionTypeQuotaExceeded,
+			Status: corev1.ConditionFalse,
+		},
+		status.Condition{
+			Type:   crcv1alpha1.ConditionTypeInvalidSize,
+			Status: corev1.ConditionFalse,
+		},
+		status.Condition{
+			Type:   crcv1alpha1.ConditionTypePolicyViolation,
+			Status: corev1.ConditionFalse,
+		},
+		status.Condition{
+			Type:   crcv1alpha1.ConditionTypeCredentialsNotRotated,
+			Status: corev1.ConditionFalse,
+		},
+		status.Condition{
+			Type:   crcv1alpha1.ConditionTypeQueued,
+			Status: corev1.ConditionFalse,
+		},
+		status.Condition{
+			Type:   crcv1alpha1.ConditionTypeEtcdBackupFailed,
+			Status: corev1.ConditionFalse,
+		},
+		status.Condition{
+			Type:   crcv1alpha1.ConditionTypeEtcdRestoreFailed,
+			Status: corev1.ConditionFalse,
+		},
+		status.Condition{
+			Type:   crcv1alpha1.ConditionTypeCertificatesExpired,
+			Status: corev1.ConditionFalse,
+		},
+		status.Condition{
+			Type:   crcv1alpha1.ConditionTypeResizePending,
+			Status: corev1.ConditionFalse,
+		},
+		status.Condition{
+			Type:   crcv1alpha1.ConditionTypeDataVolumeNotReady,
+			Status: corev1.ConditionFalse,
+		},
+		status.Condition{
+			Type:   crcv1alpha1.ConditionTypeResetInProgress,
+			Status: corev1.ConditionFalse,
+		},
 	)
+	sourceNotReady := corev1.ConditionFalse
+	if createsFromSource(crc) {
+		sourceNotReady = corev1.ConditionTrue
+	}
+	crc.Status.Conditions.SetCondition(status.Condition{
+		Type:   crcv1alpha1.ConditionTypeSourceNotReady,
+		Status: sourceNotReady,
+	})
 
 	crc, err := r.updateCrcClusterStatus(crc)
 	if err != nil {
@@ -1744,17 +2143,19 @@ func (r *ReconcileCrcCluster) ensureAPIIngressExists(logger logr.Logger, crc *cr
 }
 
 func (r *ReconcileCrcCluster) updateVirtualMachineNotReadyCondition(vm *kubevirtv1.VirtualMachine, crc *crcv1alpha1.CrcCluster) {
-	vmReady := vm.Spec.Running != nil && *vm.Spec.Running && vm.Status.Ready
+	vmReady := !vmHalted(vm) && vm.Status.Ready
 	crc.SetConditionBool(crcv1alpha1.ConditionTypeVirtualMachineNotReady, !vmReady)
 	if !vmReady {
-		// If the VM is no longer ready then we need to reconfigure
-		// everything when it comes back up
-		//
-		// TODO: If we pivot to VMs with persistent disk, this may
-		// need to change
+		// If the VM is no longer ready then we need to start kubelet
+		// and check the configuration when it comes back up. Clusters
+		// with persistent storage keep their configuration on disk,
+		// so those steps only get verified instead of redone.
 		crc.SetConditionBool(crcv1alpha1.ConditionTypeKubeletNotReady, true)
 		crc.SetConditionBool(crcv1alpha1.ConditionTypeClusterNotConfigured, true)
 		crc.SetConditionBool(crcv1alpha1.ConditionTypeReady, false)
+		if !crc.Spec.Storage.Persistent {
+			crc.Status.ConfiguredSteps = nil
+		}
 	}
 }
 
@@ -1789,60 +2190,12 @@ func (r *ReconcileCrcCluster) updateCredentials(crc *crcv1alpha1.CrcCluster) err
 	return nil
 }
 
+// bundleForCrc returns the bundle for this cluster. It doesn't check
+// the cluster against any CrcClusterPolicy, which enforcePolicy does
+// before the cluster gets created or started.
 func (r *ReconcileCrcCluster) bundleForCrc(crc *crcv1alpha1.CrcCluster) (*crcv1alpha1.CrcBundle, error) {
-	bundleName := crc.Spec.BundleName
-	if bundleName == "" {
-		bundleName = defaultBundleName
-	}
-	bundleImage := crc.Spec.BundleImage
-
-	// First, see if a BundleImage was given and exactly matches one
-	// of the predefined bundle images
-	if bundleImage != "" {
-		bundle, err := r.bundleFromImage(bundleImage)
-		if err == nil {
-			return bundle, nil
-		}
-	}
-	// Now, attempt to find the bundle by name
-	bundle, err := r.bundleFromName(bundleName)
-	if err != nil {
-		return nil, err
-	}
-	if bundleImage != "" {
-		bundle.Spec.Image = bundleImage
-	}
-	return bundle, nil
-}
-
-func (r *ReconcileCrcCluster) bundleFromImage(image string) (*crcv1alpha1.CrcBundle, error) {
-	bundleList := &crcv1alpha1.CrcBundleList{}
-	err := r.client.List(context.TODO(), bundleList, &client.ListOptions{Namespace: bundleNs})
-	if err != nil {
-		return nil, err
-	}
-	for _, bundle := range bundleList.Items {
-		if image == bundle.Spec.Image {
-			copiedBundle := bundle
-			return &copiedBundle, nil
-		}
-	}
-	return nil, fmt.Errorf("No known bundle matches image %s", image)
-}
-
-func (r *ReconcileCrcCluster) bundleFromName(name string) (*crcv1alpha1.CrcBundle, error) {
-	bundleList := &crcv1alpha1.CrcBundleList{}
-	err := r.client.List(context.TODO(), bundleList, &client.ListOptions{Namespace: bundleNs})
-	if err != nil {
-		return nil, err
-	}
-	for _, bundle := range bundleList.Items {
-		if name == bundle.Name {
-			copiedBundle := bundle
-			return &copiedBundle, nil
-		}
-	}
-	return nil, fmt.Errorf("No known bundle matches name %s", name)
+	crcBundle, _, err := bundle.ForCrcCluster(r.client, crc)
+	return crcBundle, err
 }
 
 func (r *ReconcileCrcCluster) newVirtualMachineForCrcCluster(crc *crcv1alpha1.CrcCluster, bundle *crcv1alpha1.CrcBundle) (*kubevirtv1.VirtualMachine, error) {
@@ -1861,7 +2214,6 @@ func (r *ReconcileCrcCluster) newVirtualMachineForCrcCluster(crc *crcv1alpha1.Cr
 	}
 
 	podNetwork := kubevirtv1.PodNetwork{}
-	vmRunning := !crc.Spec.Stopped
 	diskBootOrder := uint(1)
 	diskTarget := kubevirtv1.DiskTarget{
 		Bus: "virtio",
@@ -1874,9 +2226,6 @@ func (r *ReconcileCrcCluster) newVirtualMachineForCrcCluster(crc *crcv1alpha1.Cr
 		},
 		Spec: kubevirtv1.VirtualMachineInstanceSpec{
 			Domain: kubevirtv1.DomainSpec{
-				Resources: kubevirtv1.ResourceRequirements{
-					OvercommitGuestOverhead: true,
-				},
 				Devices: kubevirtv1.Devices{
 					Disks: []kubevirtv1.Disk{
 						{
@@ -1919,17 +2268,17 @@ func (r *ReconcileCrcCluster) newVirtualMachineForCrcCluster(crc *crcv1alpha1.Cr
 		},
 	}
 
+	runStrategy := runStrategyForCrcCluster(crc)
 	vm.Spec = kubevirtv1.VirtualMachineSpec{
-		Running:  &vmRunning,
-		Template: &vmTemplate,
+		RunStrategy: &runStrategy,
+		Template:    &vmTemplate,
 	}
 
-	vmCPU := kubevirtv1.CPU{
-		Sockets: uint32(crc.Spec.CPU),
-		Cores:   1,
-		Threads: 1,
+	vmCPU, err := cpuForCrcCluster(crc)
+	if err != nil {
+		return vm, err
 	}
-	vm.Spec.Template.Spec.Domain.CPU = &vmCPU
+	vm.Spec.Template.Spec.Domain.CPU = vmCPU
 
 	guestMemory, err := resource.ParseQuantity(crc.Spec.Memory)
 	if err != nil {
@@ -1940,21 +2289,24 @@ func (r *ReconcileCrcCluster) newVirtualMachineForCrcCluster(crc *crcv1alpha1.Cr
 	}
 	vm.Spec.Template.Spec.Domain.Memory = &vmMemory
 
-	vmRequestCPU := crc.Spec.CPU / 2
-	if vmRequestCPU < 2 {
-		vmRequestCPU = 2
+	vmResources, err := vmResourcesForCrcCluster(crc, bundle)
+	if err != nil {
+		return vm, err
 	}
-	vmResources := corev1.ResourceList{
-		corev1.ResourceCPU:    resource.MustParse(fmt.Sprintf("%d", vmRequestCPU)),
-		corev1.ResourceMemory: guestMemory,
+	vm.Spec.Template.Spec.Domain.Resources = vmResources
+
+	placement, err := placementForCrcCluster(crc)
+	if err != nil {
+		return vm, err
 	}
-	vm.Spec.Template.Spec.Domain.Resources.Requests = vmResources
+	applyPlacement(&vm.Spec.Template.Spec, placement)
 
+	claimAccessModes := map[string][]corev1.PersistentVolumeAccessMode{}
 	storageSpec := crc.Spec.Storage
 	if storageSpec.Persistent {
 		// Persistent, so use a DataVolume to import the container
 		// image into a new PVC.
-		dataVolumeName := fmt.Sprintf("%s-datavolume", crc.Name)
+		dataVolumeName := crcv1alpha1.RootVolumeName(crc.Name)
 
 		bundleQuantity, err := resource.ParseQuantity(bundle.Spec.DiskSize)
 		if err != nil {
@@ -1973,20 +2325,17 @@ func (r *ReconcileCrcCluster) newVirtualMachineForCrcCluster(crc *crcv1alpha1.Cr
 			storageQuantity = bundleQuantity
 		}
 
+		pvcSpec, err := pvcSpecForCrcCluster(crc, storageQuantity)
+		if err != nil {
+			return vm, err
+		}
 		dataVolumeTemplate := cdiv1.DataVolume{
 			ObjectMeta: metav1.ObjectMeta{
 				Name: dataVolumeName,
 			},
 			Spec: cdiv1.DataVolumeSpec{
 				Source: cdiv1.DataVolumeSource{},
-				PVC: &corev1.PersistentVolumeClaimSpec{
-					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-					Resources: corev1.ResourceRequirements{
-						Requests: corev1.ResourceList{
-							corev1.ResourceStorage: storageQuantity,
-						},
-					},
-				},
+				PVC:    pvcSpec,
 			},
 		}
 		if bundle.Spec.URL != "" {
@@ -1998,12 +2347,31 @@ func (r *ReconcileCrcCluster) newVirtualMachineForCrcCluster(crc *crcv1alpha1.Cr
 				URL: fmt.Sprintf("docker://%s", bundle.Spec.Image),
 			}
 		}
-		vm.Spec.DataVolumeTemplates = []cdiv1.DataVolume{dataVolumeTemplate}
+		if restoresSnapshot(crc) {
+			// Restored from a snapshot, so use the
+			// PersistentVolumeClaim created from its VolumeSnapshot
+			vm.Spec.Template.Spec.Volumes[0].VolumeSource = kubevirtv1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: dataVolumeName,
+				},
+			}
+			restoredPVC := &corev1.PersistentVolumeClaim{}
+			err := r.client.Get(context.TODO(), types.NamespacedName{Name: dataVolumeName, Namespace: crc.Namespace}, restoredPVC)
+			if err != nil {
+				return vm, err
+			}
+			claimAccessModes[dataVolumeName] = restoredPVC.Spec.AccessModes
+		} else {
+			vm.Spec.DataVolumeTemplates = []cdiv1.DataVolume{dataVolumeTemplate}
 
-		vm.Spec.Template.Spec.Volumes[0].VolumeSource = kubevirtv1.VolumeSource{
-			DataVolume: &kubevirtv1.DataVolumeSource{
-				Name: dataVolumeName,
-			},
+			vm.Spec.Template.Spec.Volumes[0].VolumeSource = kubevirtv1.VolumeSource{
+				DataVolume: &kubevirtv1.DataVolumeSource{
+					Name: dataVolumeName,
+				},
+			}
+			if err := applyDataVolumeSource(vm, crc); err != nil {
+				return vm, err
+			}
 		}
 	} else {
 		// Not persisent, so use the bundle's container image directly
@@ -2012,7 +2380,16 @@ func (r *ReconcileCrcCluster) newVirtualMachineForCrcCluster(crc *crcv1alpha1.Cr
 				Image: bundle.Spec.Image,
 			},
 		}
+		if err := applyScratchDisk(vm, crc, bundle); err != nil {
+			return vm, err
+		}
+	}
+
+	if err := applyAdditionalDisks(vm, crc); err != nil {
+		return vm, err
 	}
+	applyPerformance(&vm.Spec.Template.Spec, crc)
+	applyEvictionStrategy(vm, claimAccessModes)
 
 	if err := controllerutil.SetControllerReference(crc, vm, r.scheme); err != nil {
 		return vm, err
@@ -0,0 +1,108 @@
+package crccluster
+
+import (
+	"encoding/base64"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	sshClient "github.com/code-ready/machine/libmachine/ssh"
+	"github.com/go-logr/logr"
+	"golang.org/x/crypto/ssh"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// acceptClaim flags the credentials of a cluster claimed from a pool
+// for rotation, once per claim. The claim label the claim controller
+// puts on the cluster is the only record of the binding, so this
+// picks up a binding no matter where the claim controller got
+// interrupted. The claim only hands out credentials once the cluster
+// records it here and the rotation is done. The cluster's maximum
+// lifetime starts over for the claimer.
+func (r *ReconcileCrcCluster) acceptClaim(logger logr.Logger, crc *crcv1alpha1.CrcCluster) (*crcv1alpha1.CrcCluster, error) {
+	claimName := crc.Labels[crcv1alpha1.ClaimLabel]
+	if claimName == "" || crc.Status.Claim == claimName {
+		return crc, nil
+	}
+	logger.Info("Flagging credentials of claimed CrcCluster for rotation.", "Claim", claimName)
+	crc.Status.Claim = claimName
+	crc.Status.KubeAdminPassword = ""
+	crc.Status.LifetimeStartTime = nil
+	crc.SetConditionBool(crcv1alpha1.ConditionTypeCredentialsNotRotated, true)
+	crc.SetConditionBool(crcv1alpha1.ConditionTypeReady, false)
+	return r.updateCrcClusterStatus(crc)
+}
+
+// rotateCredentials gives a cluster claimed from a pool, or cloned
+// from another cluster, a new kubeadmin password, admin client
+// certificate, pull secret, and SSH key so nobody with access to the
+// pool or the original cluster can use it afterwards. acceptClaim
+// already cleared the old password, which updateCredentials
+// regenerated earlier in this reconcile.
+//
+// The old admin client certificate can't be revoked and stays valid
+// until it expires.
+func (r *ReconcileCrcCluster) rotateCredentials(crc *crcv1alpha1.CrcCluster, sshClient *sshClient.NativeClient, k8sClient *kubernetes.Clientset) (*crcv1alpha1.CrcCluster, error) {
+	if err := r.updateClusterAdminUser(crc, k8sClient); err != nil {
+		return crc, err
+	}
+
+	if err := r.updatePullSecret(crc, sshClient, k8sClient); err != nil {
+		return crc, err
+	}
+
+	// Issue a new admin client certificate for a new key
+	err := k8sClient.CertificatesV1beta1().CertificateSigningRequests().Delete("crc-cluster-admin", &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return crc, err
+	}
+	crc.Status.KubeAdminClientKey = ""
+	crc.Status.Kubeconfig = ""
+	if err := r.updateCredentials(crc); err != nil {
+		return crc, err
+	}
+	crc, err = r.updateClusterAdminCert(crc, k8sClient)
+	if err != nil {
+		return crc, err
+	}
+
+	crc, err = r.rotateSSHKey(crc, sshClient)
+	if err != nil {
+		return crc, err
+	}
+
+	crc.SetConditionBool(crcv1alpha1.ConditionTypeCredentialsNotRotated, false)
+	return r.updateCrcClusterStatus(crc)
+}
+
+// rotateSSHKey replaces the cluster's SSH key. The new key gets
+// authorized alongside the old one and saved in the status before the
+// old one gets removed, so an interrupted rotation never locks the
+// operator out. ensureUniqueSSHKey picks up the new key on the next
+// reconcile if saving it failed.
+func (r *ReconcileCrcCluster) rotateSSHKey(crc *crcv1alpha1.CrcCluster, sshClient *sshClient.NativeClient) (*crcv1alpha1.CrcCluster, error) {
+	rotateKeyScript := `
+cd ~/.ssh
+rm -f crc_operator crc_operator.pub
+ssh-keygen -q -t rsa -b 4096 -N '' -f crc_operator -C 'core@crc-operator'
+cat crc_operator.pub >> authorized_keys
+cat crc_operator
+`
+	output, err := sshQuickOutput(sshClient, rotateKeyScript)
+	if err != nil {
+		return crc, err
+	}
+	clusterPrivateKey := []byte(output)
+	if _, err := ssh.ParsePrivateKey(clusterPrivateKey); err != nil {
+		return crc, err
+	}
+	crc.Status.SSHKey = base64.StdEncoding.EncodeToString(clusterPrivateKey)
+	crc, err = r.updateCrcClusterStatus(crc)
+	if err != nil {
+		return crc, err
+	}
+
+	// This is the last command run with the old key
+	_, err = sshQuickOutput(sshClient, "cp ~/.ssh/crc_operator.pub ~/.ssh/authorized_keys")
+	return crc, err
+}
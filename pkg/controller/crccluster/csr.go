@@ -0,0 +1,214 @@
+package crccluster
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/go-logr/logr"
+	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// csrApprovalInterval is how often Ready clusters get checked for
+// pending CSRs, such as kubelet certificate renewals
+var csrApprovalInterval = os.Getenv("CSR_APPROVAL_INTERVAL")
+
+const defaultCSRApprovalInterval = 5 * time.Minute
+
+const (
+	nodeBootstrapperUser string = "system:serviceaccount:openshift-machine-config-operator:node-bootstrapper"
+	nodeUserPrefix       string = "system:node:"
+	nodesGroup           string = "system:nodes"
+)
+
+// approveCSRs approves pending kubelet client and serving CSRs that
+// belong to the cluster's Node and denies ones that claim to but
+// don't match it. CSRs from anyone else are left alone. Every
+// decision is recorded as an Event on the CrcCluster.
+func (r *ReconcileCrcCluster) approveCSRs(logger logr.Logger, crc *crcv1alpha1.CrcCluster, k8sClient *kubernetes.Clientset) error {
+	csrs, err := k8sClient.CertificatesV1beta1().CertificateSigningRequests().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var nodes *corev1.NodeList
+	for _, csr := range csrs.Items {
+		if csrDecided(&csr) {
+			continue
+		}
+		if csr.Spec.Username != nodeBootstrapperUser && !strings.HasPrefix(csr.Spec.Username, nodeUserPrefix) {
+			continue
+		}
+		if nodes == nil {
+			nodes, err = k8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := validateNodeCSR(&csr, nodes.Items); err != nil {
+			logger.Info("Denying CSR.", "CSR", csr.Name, "Username", csr.Spec.Username, "Reason", err.Error())
+			if err := denyCSR(&csr, k8sClient, err.Error()); err != nil {
+				return err
+			}
+			r.recorder.Eventf(crc, corev1.EventTypeWarning, "CSRDenied", "Denied CSR %s from %s: %v", csr.Name, csr.Spec.Username, err)
+			continue
+		}
+		logger.Info("Approving CSR.", "CSR", csr.Name, "Username", csr.Spec.Username)
+		if err := approveCSR(&csr, k8sClient); err != nil {
+			return err
+		}
+		r.recorder.Eventf(crc, corev1.EventTypeNormal, "CSRApproved", "Approved CSR %s from %s", csr.Name, csr.Spec.Username)
+	}
+	return nil
+}
+
+// csrApprovalIntervalForCrcCluster returns how often to check a Ready
+// cluster for pending CSRs
+func csrApprovalIntervalForCrcCluster() (time.Duration, error) {
+	if csrApprovalInterval != "" {
+		interval, err := time.ParseDuration(csrApprovalInterval)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid CSR_APPROVAL_INTERVAL environment variable: %v", err)
+		}
+		return interval, nil
+	}
+	return defaultCSRApprovalInterval, nil
+}
+
+func csrDecided(csr *certificatesv1beta1.CertificateSigningRequest) bool {
+	for _, condition := range csr.Status.Conditions {
+		if condition.Type == certificatesv1beta1.CertificateApproved || condition.Type == certificatesv1beta1.CertificateDenied {
+			return true
+		}
+	}
+	return false
+}
+
+// validateNodeCSR returns an error unless the CSR is a kubelet client
+// certificate request from the node-bootstrapper or a Node, or a
+// kubelet serving certificate request from a Node, for one of the
+// given Nodes
+func validateNodeCSR(csr *certificatesv1beta1.CertificateSigningRequest, nodes []corev1.Node) error {
+	block, _ := pem.Decode(csr.Spec.Request)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return fmt.Errorf("request is not a PEM-encoded certificate request")
+	}
+	request, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("unable to parse certificate request: %v", err)
+	}
+
+	if !reflect.DeepEqual(request.Subject.Organization, []string{nodesGroup}) {
+		return fmt.Errorf("subject organization %v is not [%s]", request.Subject.Organization, nodesGroup)
+	}
+	if !strings.HasPrefix(request.Subject.CommonName, nodeUserPrefix) {
+		return fmt.Errorf("subject common name %s is not a node", request.Subject.CommonName)
+	}
+	nodeName := strings.TrimPrefix(request.Subject.CommonName, nodeUserPrefix)
+	var node *corev1.Node
+	for i := range nodes {
+		if nodes[i].Name == nodeName {
+			node = &nodes[i]
+		}
+	}
+	if node == nil {
+		return fmt.Errorf("node %s is not part of this cluster", nodeName)
+	}
+	if csr.Spec.Username != nodeBootstrapperUser && csr.Spec.Username != request.Subject.CommonName {
+		return fmt.Errorf("node %s requested a certificate for %s", csr.Spec.Username, request.Subject.CommonName)
+	}
+	if len(request.EmailAddresses) > 0 || len(request.URIs) > 0 {
+		return fmt.Errorf("request has email or URI subject alternative names")
+	}
+
+	if hasOnlyUsages(csr, certificatesv1beta1.UsageClientAuth) {
+		if len(request.DNSNames) > 0 || len(request.IPAddresses) > 0 {
+			return fmt.Errorf("client certificate request has subject alternative names")
+		}
+		return nil
+	}
+
+	if !hasOnlyUsages(csr, certificatesv1beta1.UsageServerAuth) {
+		return fmt.Errorf("unexpected usages %v", csr.Spec.Usages)
+	}
+	if csr.Spec.Username == nodeBootstrapperUser {
+		return fmt.Errorf("serving certificate requested by the node-bootstrapper")
+	}
+	if len(request.DNSNames) == 0 && len(request.IPAddresses) == 0 {
+		return fmt.Errorf("serving certificate request has no subject alternative names")
+	}
+	for _, dnsName := range request.DNSNames {
+		if !nodeHasAddress(node, dnsName) {
+			return fmt.Errorf("DNS name %s is not an address of node %s", dnsName, nodeName)
+		}
+	}
+	for _, ip := range request.IPAddresses {
+		if !nodeHasAddress(node, ip.String()) {
+			return fmt.Errorf("IP address %s is not an address of node %s", ip, nodeName)
+		}
+	}
+	return nil
+}
+
+// hasOnlyUsages returns true if the CSR asks for the given extended
+// usage plus only digital signature and key encipherment
+func hasOnlyUsages(csr *certificatesv1beta1.CertificateSigningRequest, usage certificatesv1beta1.KeyUsage) bool {
+	found := false
+	for _, requested := range csr.Spec.Usages {
+		switch requested {
+		case usage:
+			found = true
+		case certificatesv1beta1.UsageDigitalSignature, certificatesv1beta1.UsageKeyEncipherment:
+		default:
+			return false
+		}
+	}
+	return found
+}
+
+func nodeHasAddress(node *corev1.Node, address string) bool {
+	if address == node.Name {
+		return true
+	}
+	for _, nodeAddress := range node.Status.Addresses {
+		if nodeAddress.Address == address {
+			return true
+		}
+		// Compare IPs in their canonical form
+		if ip := net.ParseIP(address); ip != nil && ip.Equal(net.ParseIP(nodeAddress.Address)) {
+			return true
+		}
+	}
+	return false
+}
+
+func approveCSR(csr *certificatesv1beta1.CertificateSigningRequest, k8sClient *kubernetes.Clientset) error {
+	csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1beta1.CertificateSigningRequestCondition{
+		Type:           certificatesv1beta1.CertificateApproved,
+		Reason:         "CRCApprove",
+		Message:        "This CSR was approved by CodeReady Containers operator.",
+		LastUpdateTime: metav1.Now(),
+	})
+	_, err := k8sClient.CertificatesV1beta1().CertificateSigningRequests().UpdateApproval(csr)
+	return err
+}
+
+func denyCSR(csr *certificatesv1beta1.CertificateSigningRequest, k8sClient *kubernetes.Clientset, reason string) error {
+	csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1beta1.CertificateSigningRequestCondition{
+		Type:           certificatesv1beta1.CertificateDenied,
+		Reason:         "CRCDeny",
+		Message:        fmt.Sprintf("This CSR was denied by CodeReady Containers operator: %s.", reason),
+		LastUpdateTime: metav1.Now(),
+	})
+	_, err := k8sClient.CertificatesV1beta1().CertificateSigningRequests().UpdateApproval(csr)
+	return err
+}
@@ -0,0 +1,228 @@
+package crccluster
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+
+	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	clientUsages  = []certificatesv1beta1.KeyUsage{certificatesv1beta1.UsageDigitalSignature, certificatesv1beta1.UsageKeyEncipherment, certificatesv1beta1.UsageClientAuth}
+	servingUsages = []certificatesv1beta1.KeyUsage{certificatesv1beta1.UsageDigitalSignature, certificatesv1beta1.UsageKeyEncipherment, certificatesv1beta1.UsageServerAuth}
+)
+
+func newTestCSR(t *testing.T, username string, usages []certificatesv1beta1.KeyUsage, template *x509.CertificateRequest) *certificatesv1beta1.CertificateSigningRequest {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &certificatesv1beta1.CertificateSigningRequest{
+		Spec: certificatesv1beta1.CertificateSigningRequestSpec{
+			Request:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}),
+			Username: username,
+			Usages:   usages,
+		},
+	}
+}
+
+func nodeSubject(nodeName string) pkix.Name {
+	return pkix.Name{CommonName: nodeUserPrefix + nodeName, Organization: []string{nodesGroup}}
+}
+
+func TestValidateNodeCSR(t *testing.T) {
+	nodes := []corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "crc-node"},
+			Status: corev1.NodeStatus{
+				Addresses: []corev1.NodeAddress{
+					{Type: corev1.NodeInternalIP, Address: "192.168.126.11"},
+					{Type: corev1.NodeHostName, Address: "crc-node.example.com"},
+				},
+			},
+		},
+	}
+	nodeUser := nodeUserPrefix + "crc-node"
+	exampleURI, _ := url.Parse("spiffe://example.com/crc-node")
+
+	tests := []struct {
+		name     string
+		username string
+		usages   []certificatesv1beta1.KeyUsage
+		template *x509.CertificateRequest
+		err      string
+	}{
+		{
+			name:     "client certificate from the node-bootstrapper",
+			username: nodeBootstrapperUser,
+			usages:   clientUsages,
+			template: &x509.CertificateRequest{Subject: nodeSubject("crc-node")},
+		},
+		{
+			name:     "client certificate renewal from the Node",
+			username: nodeUser,
+			usages:   clientUsages,
+			template: &x509.CertificateRequest{Subject: nodeSubject("crc-node")},
+		},
+		{
+			name:     "client certificate with subject alternative names",
+			username: nodeUser,
+			usages:   clientUsages,
+			template: &x509.CertificateRequest{Subject: nodeSubject("crc-node"), DNSNames: []string{"crc-node"}},
+			err:      "client certificate request has subject alternative names",
+		},
+		{
+			name:     "client certificate for another Node",
+			username: nodeUserPrefix + "other-node",
+			usages:   clientUsages,
+			template: &x509.CertificateRequest{Subject: nodeSubject("crc-node")},
+			err:      "node system:node:other-node requested a certificate for system:node:crc-node",
+		},
+		{
+			name:     "client certificate for a Node outside the cluster",
+			username: nodeBootstrapperUser,
+			usages:   clientUsages,
+			template: &x509.CertificateRequest{Subject: nodeSubject("other-node")},
+			err:      "node other-node is not part of this cluster",
+		},
+		{
+			name:     "client certificate outside the nodes group",
+			username: nodeUser,
+			usages:   clientUsages,
+			template: &x509.CertificateRequest{Subject: pkix.Name{CommonName: nodeUser, Organization: []string{"system:masters"}}},
+			err:      "subject organization [system:masters] is not [system:nodes]",
+		},
+		{
+			name:     "client certificate for a user",
+			username: nodeBootstrapperUser,
+			usages:   clientUsages,
+			template: &x509.CertificateRequest{Subject: pkix.Name{CommonName: "kube:admin", Organization: []string{nodesGroup}}},
+			err:      "subject common name kube:admin is not a node",
+		},
+		{
+			name:     "serving certificate from the Node",
+			username: nodeUser,
+			usages:   servingUsages,
+			template: &x509.CertificateRequest{
+				Subject:     nodeSubject("crc-node"),
+				DNSNames:    []string{"crc-node", "crc-node.example.com"},
+				IPAddresses: []net.IP{net.ParseIP("192.168.126.11")},
+			},
+		},
+		{
+			name:     "serving certificate from the node-bootstrapper",
+			username: nodeBootstrapperUser,
+			usages:   servingUsages,
+			template: &x509.CertificateRequest{Subject: nodeSubject("crc-node"), DNSNames: []string{"crc-node"}},
+			err:      "serving certificate requested by the node-bootstrapper",
+		},
+		{
+			name:     "serving certificate for another Node",
+			username: nodeUserPrefix + "other-node",
+			usages:   servingUsages,
+			template: &x509.CertificateRequest{Subject: nodeSubject("crc-node"), DNSNames: []string{"crc-node"}},
+			err:      "node system:node:other-node requested a certificate for system:node:crc-node",
+		},
+		{
+			name:     "serving certificate with a DNS name that isn't a Node address",
+			username: nodeUser,
+			usages:   servingUsages,
+			template: &x509.CertificateRequest{Subject: nodeSubject("crc-node"), DNSNames: []string{"api.example.com"}},
+			err:      "DNS name api.example.com is not an address of node crc-node",
+		},
+		{
+			name:     "serving certificate with an IP address that isn't a Node address",
+			username: nodeUser,
+			usages:   servingUsages,
+			template: &x509.CertificateRequest{Subject: nodeSubject("crc-node"), IPAddresses: []net.IP{net.ParseIP("10.0.0.1")}},
+			err:      "IP address 10.0.0.1 is not an address of node crc-node",
+		},
+		{
+			name:     "serving certificate without subject alternative names",
+			username: nodeUser,
+			usages:   servingUsages,
+			template: &x509.CertificateRequest{Subject: nodeSubject("crc-node")},
+			err:      "serving certificate request has no subject alternative names",
+		},
+		{
+			name:     "serving certificate with an email address",
+			username: nodeUser,
+			usages:   servingUsages,
+			template: &x509.CertificateRequest{Subject: nodeSubject("crc-node"), DNSNames: []string{"crc-node"}, EmailAddresses: []string{"admin@example.com"}},
+			err:      "request has email or URI subject alternative names",
+		},
+		{
+			name:     "client certificate with a URI",
+			username: nodeUser,
+			usages:   clientUsages,
+			template: &x509.CertificateRequest{Subject: nodeSubject("crc-node"), URIs: []*url.URL{exampleURI}},
+			err:      "request has email or URI subject alternative names",
+		},
+		{
+			name:     "client and serving usages together",
+			username: nodeUser,
+			usages:   append(append([]certificatesv1beta1.KeyUsage{}, clientUsages...), certificatesv1beta1.UsageServerAuth),
+			template: &x509.CertificateRequest{Subject: nodeSubject("crc-node"), DNSNames: []string{"crc-node"}},
+			err:      "unexpected usages",
+		},
+		{
+			name:     "extra usages",
+			username: nodeUser,
+			usages:   append(append([]certificatesv1beta1.KeyUsage{}, clientUsages...), certificatesv1beta1.UsageCertSign),
+			template: &x509.CertificateRequest{Subject: nodeSubject("crc-node")},
+			err:      "unexpected usages",
+		},
+		{
+			name:     "no usages",
+			username: nodeUser,
+			template: &x509.CertificateRequest{Subject: nodeSubject("crc-node")},
+			err:      "unexpected usages",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			csr := newTestCSR(t, test.username, test.usages, test.template)
+			err := validateNodeCSR(csr, nodes)
+			if test.err == "" {
+				if err != nil {
+					t.Errorf("Expected CSR to be valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+				t.Errorf("Expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestValidateNodeCSRMalformed(t *testing.T) {
+	csr := &certificatesv1beta1.CertificateSigningRequest{
+		Spec: certificatesv1beta1.CertificateSigningRequestSpec{
+			Request:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("not a request")}),
+			Username: nodeBootstrapperUser,
+			Usages:   clientUsages,
+		},
+	}
+	if err := validateNodeCSR(csr, nil); err == nil {
+		t.Errorf("Expected a PEM block that isn't a certificate request to be rejected")
+	}
+	csr.Spec.Request = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: []byte("not a request")})
+	if err := validateNodeCSR(csr, nil); err == nil {
+		t.Errorf("Expected an unparseable certificate request to be rejected")
+	}
+}
@@ -0,0 +1,112 @@
+package crccluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+)
+
+// updateDataVolumeStatus copies the progress of importing the bundle
+// into a cluster's persistent volume into its status, so it's clear
+// whether the cluster is still importing or the import failed
+func (r *ReconcileCrcCluster) updateDataVolumeStatus(logger logr.Logger, crc *crcv1alpha1.CrcCluster, vm *kubevirtv1.VirtualMachine) error {
+	rootDataVolume := rootDataVolumeTemplate(vm)
+	if rootDataVolume == nil {
+		crc.Status.DataVolume = nil
+		crc.SetConditionBool(crcv1alpha1.ConditionTypeDataVolumeNotReady, false)
+		return nil
+	}
+	name := rootDataVolume.Name
+
+	dataVolume := &cdiv1.DataVolume{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: vm.Namespace}, dataVolume)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// KubeVirt hasn't created the DataVolume yet
+			crc.Status.DataVolume = &crcv1alpha1.CrcClusterDataVolumeStatus{Name: name}
+			crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeDataVolumeNotReady, true, "Pending", "Waiting on the DataVolume to be created")
+			return nil
+		}
+		logger.Error(err, "Failed to get DataVolume.")
+		return err
+	}
+
+	previousPhase := ""
+	if crc.Status.DataVolume != nil {
+		previousPhase = crc.Status.DataVolume.Phase
+	}
+	dataVolumeStatus := &crcv1alpha1.CrcClusterDataVolumeStatus{
+		Name:     name,
+		Phase:    string(dataVolume.Status.Phase),
+		Progress: string(dataVolume.Status.Progress),
+	}
+	crc.Status.DataVolume = dataVolumeStatus
+
+	switch dataVolume.Status.Phase {
+	case cdiv1.Succeeded:
+		crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeDataVolumeNotReady, false, "Imported", "The bundle was imported into the persistent volume")
+		return nil
+	case cdiv1.Failed:
+		r.updateImportError(logger, dataVolume, dataVolumeStatus)
+		message := "Importing the bundle into the persistent volume failed"
+		if dataVolumeStatus.ImportError != "" {
+			message = fmt.Sprintf("%s: %s", message, dataVolumeStatus.ImportError)
+		}
+		crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeDataVolumeNotReady, true, "ImportFailed", message)
+		if previousPhase != string(cdiv1.Failed) {
+			r.recorder.Event(crc, corev1.EventTypeWarning, "ImportFailed", message)
+		}
+		return nil
+	}
+
+	r.updateImportError(logger, dataVolume, dataVolumeStatus)
+	if dataVolumeStatus.ImportRestarts > 0 {
+		crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeDataVolumeNotReady, true, "ImportRetrying", fmt.Sprintf("Retrying the import after %d failed attempts, the last one with: %s", dataVolumeStatus.ImportRestarts, dataVolumeStatus.ImportError))
+		return nil
+	}
+	message := fmt.Sprintf("The DataVolume is %s", dataVolume.Status.Phase)
+	if dataVolume.Status.Progress != "" && dataVolume.Status.Progress != "N/A" {
+		message = fmt.Sprintf("%s, %s done", message, dataVolume.Status.Progress)
+	}
+	crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeDataVolumeNotReady, true, "Importing", message)
+	return nil
+}
+
+// updateImportError fills in how many times CDI's importer pod
+// restarted and the error it last failed with. The pod is read
+// straight from the API server so the operator doesn't have to cache
+// every pod.
+func (r *ReconcileCrcCluster) updateImportError(logger logr.Logger, dataVolume *cdiv1.DataVolume, dataVolumeStatus *crcv1alpha1.CrcClusterDataVolumeStatus) {
+	pod := &corev1.Pod{}
+	err := r.restClient.Get().
+		Namespace(dataVolume.Namespace).
+		Resource("pods").
+		Name(fmt.Sprintf("importer-%s", dataVolume.Name)).
+		Do().
+		Into(pod)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Info("Unable to get importer pod.", "Error", err.Error())
+		}
+		return
+	}
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		dataVolumeStatus.ImportRestarts += int(containerStatus.RestartCount)
+		for _, terminated := range []*corev1.ContainerStateTerminated{containerStatus.State.Terminated, containerStatus.LastTerminationState.Terminated} {
+			if terminated != nil && terminated.ExitCode != 0 && dataVolumeStatus.ImportError == "" {
+				dataVolumeStatus.ImportError = strings.TrimSpace(terminated.Message)
+				if dataVolumeStatus.ImportError == "" {
+					dataVolumeStatus.ImportError = terminated.Reason
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package crccluster
+
+import (
+	"fmt"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	sshClient "github.com/code-ready/machine/libmachine/ssh"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+)
+
+const (
+	// localStorageClassName is the StorageClass of the
+	// PersistentVolumes backed by additional disks inside the cluster
+	localStorageClassName = "local-storage"
+
+	// localStorageMountPath is where additional disks get mounted
+	// inside the cluster's VirtualMachine
+	localStorageMountPath = "/var/mnt/local-storage"
+
+	// additionalDiskLabel marks the PersistentVolumes inside the
+	// cluster that are backed by additional disks
+	additionalDiskLabel = "crc.developer.openshift.io/additional-disk"
+)
+
+// applyAdditionalDisks attaches a cluster's additional disks to its
+// VirtualMachine. The disk's name is used as its serial number so it
+// shows up as /dev/disk/by-id/virtio-<name> inside the VirtualMachine.
+func applyAdditionalDisks(vm *kubevirtv1.VirtualMachine, crc *crcv1alpha1.CrcCluster) error {
+	vmiSpec := &vm.Spec.Template.Spec
+	for _, additionalDisk := range crc.Spec.Storage.AdditionalDisks {
+		dataVolumeName := fmt.Sprintf("%s-%s", crc.Name, additionalDisk.Name)
+		if additionalDisk.Name == scratchDiskName || dataVolumeName == crcv1alpha1.RootVolumeName(crc.Name) {
+			return fmt.Errorf("Additional disk name %s is reserved", additionalDisk.Name)
+		}
+		size, err := resource.ParseQuantity(additionalDisk.Size)
+		if err != nil {
+			return fmt.Errorf("Invalid size %s of additional disk %s: %v", additionalDisk.Size, additionalDisk.Name, err)
+		}
+
+		diskName := fmt.Sprintf("disk-%s", additionalDisk.Name)
+		vmiSpec.Domain.Devices.Disks = append(vmiSpec.Domain.Devices.Disks, kubevirtv1.Disk{
+			Name:   diskName,
+			Serial: additionalDisk.Name,
+			DiskDevice: kubevirtv1.DiskDevice{
+				Disk: &kubevirtv1.DiskTarget{
+					Bus: "virtio",
+				},
+			},
+		})
+
+		volume := kubevirtv1.Volume{Name: diskName}
+		if additionalDisk.Persistent {
+			pvcSpec, err := pvcSpecForCrcCluster(crc, size)
+			if err != nil {
+				return err
+			}
+			if additionalDisk.StorageClassName != "" {
+				storageClassName := additionalDisk.StorageClassName
+				pvcSpec.StorageClassName = &storageClassName
+			}
+			vm.Spec.DataVolumeTemplates = append(vm.Spec.DataVolumeTemplates, cdiv1.DataVolume{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: dataVolumeName,
+				},
+				Spec: cdiv1.DataVolumeSpec{
+					Source: cdiv1.DataVolumeSource{
+						Blank: &cdiv1.DataVolumeBlankImage{},
+					},
+					PVC: pvcSpec,
+				},
+			})
+			volume.VolumeSource = kubevirtv1.VolumeSource{
+				DataVolume: &kubevirtv1.DataVolumeSource{
+					Name: dataVolumeName,
+				},
+			}
+		} else {
+			volume.VolumeSource = kubevirtv1.VolumeSource{
+				EmptyDisk: &kubevirtv1.EmptyDiskSource{
+					Capacity: size,
+				},
+			}
+		}
+		vmiSpec.Volumes = append(vmiSpec.Volumes, volume)
+	}
+	return nil
+}
+
+// mountAdditionalDisks formats any additional disks that don't have a
+// filesystem yet and mounts them where their local PersistentVolumes
+// expect them. Mounts don't survive a reboot, so this runs every time
+// before the Kubelet gets started.
+func (r *ReconcileCrcCluster) mountAdditionalDisks(logger logr.Logger, sshClient *sshClient.NativeClient, crc *crcv1alpha1.CrcCluster) error {
+	for _, additionalDisk := range crc.Spec.Storage.AdditionalDisks {
+		logger.Info("Mounting additional disk.", "Disk", additionalDisk.Name)
+		output, err := sshQuickOutput(sshClient, mountAdditionalDiskScript(additionalDisk.Name))
+		if err != nil {
+			logger.Error(err, "Error mounting additional disk.", "Disk", additionalDisk.Name, "Output", output)
+			return err
+		}
+	}
+	return nil
+}
+
+func mountAdditionalDiskScript(name string) string {
+	return fmt.Sprintf(`set -e
+DEVICE=/dev/disk/by-id/virtio-%[1]s
+MOUNT_PATH=%[2]s/%[1]s
+sudo udevadm settle
+if ! sudo blkid $DEVICE; then
+  sudo mkfs.xfs -L %[1]s $DEVICE
+fi
+sudo mkdir -p $MOUNT_PATH
+if ! mountpoint -q $MOUNT_PATH; then
+  sudo mount $DEVICE $MOUNT_PATH
+fi
+sudo chcon -R -t container_file_t $MOUNT_PATH
+`, name, localStorageMountPath)
+}
+
+// ensureLocalStorage registers the cluster's additional disks inside
+// the cluster as local PersistentVolumes of the local-storage
+// StorageClass. PersistentVolumes of disks that were removed get
+// deleted unless something still claims them.
+func (r *ReconcileCrcCluster) ensureLocalStorage(logger logr.Logger, crc *crcv1alpha1.CrcCluster, k8sClient *kubernetes.Clientset) error {
+	existingPVs, err := k8sClient.CoreV1().PersistentVolumes().List(metav1.ListOptions{LabelSelector: additionalDiskLabel})
+	if err != nil {
+		return err
+	}
+	wanted := map[string]bool{}
+	for _, additionalDisk := range crc.Spec.Storage.AdditionalDisks {
+		wanted[localPVName(additionalDisk.Name)] = true
+	}
+	for _, pv := range existingPVs.Items {
+		if wanted[pv.Name] || pv.Status.Phase == corev1.VolumeBound {
+			continue
+		}
+		logger.Info("Deleting PersistentVolume of removed additional disk.", "PersistentVolume.Name", pv.Name)
+		if err := k8sClient.CoreV1().PersistentVolumes().Delete(pv.Name, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	if len(crc.Spec.Storage.AdditionalDisks) == 0 {
+		return nil
+	}
+
+	if err := ensureLocalStorageClass(k8sClient); err != nil {
+		return err
+	}
+
+	nodes, err := k8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	if len(nodes.Items) == 0 {
+		return fmt.Errorf("No nodes found to attach local storage to")
+	}
+	hostname := nodes.Items[0].Labels[corev1.LabelHostname]
+	if hostname == "" {
+		hostname = nodes.Items[0].Name
+	}
+
+	for _, additionalDisk := range crc.Spec.Storage.AdditionalDisks {
+		pv, err := newLocalPVForAdditionalDisk(additionalDisk, hostname)
+		if err != nil {
+			return err
+		}
+		_, err = k8sClient.CoreV1().PersistentVolumes().Get(pv.Name, metav1.GetOptions{})
+		if err == nil {
+			continue
+		}
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		logger.Info("Creating PersistentVolume for additional disk.", "PersistentVolume.Name", pv.Name)
+		if _, err := k8sClient.CoreV1().PersistentVolumes().Create(pv); err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func ensureLocalStorageClass(k8sClient *kubernetes.Clientset) error {
+	_, err := k8sClient.StorageV1().StorageClasses().Get(localStorageClassName, metav1.GetOptions{})
+	if err == nil || !errors.IsNotFound(err) {
+		return err
+	}
+	bindingMode := storagev1.VolumeBindingWaitForFirstConsumer
+	reclaimPolicy := corev1.PersistentVolumeReclaimRetain
+	storageClass := &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: localStorageClassName,
+		},
+		Provisioner:       "kubernetes.io/no-provisioner",
+		ReclaimPolicy:     &reclaimPolicy,
+		VolumeBindingMode: &bindingMode,
+	}
+	_, err = k8sClient.StorageV1().StorageClasses().Create(storageClass)
+	if errors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func newLocalPVForAdditionalDisk(additionalDisk crcv1alpha1.CrcAdditionalDisk, hostname string) (*corev1.PersistentVolume, error) {
+	size, err := resource.ParseQuantity(additionalDisk.Size)
+	if err != nil {
+		return nil, err
+	}
+	volumeMode := corev1.PersistentVolumeFilesystem
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: localPVName(additionalDisk.Name),
+			Labels: map[string]string{
+				additionalDiskLabel: additionalDisk.Name,
+			},
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceStorage: size,
+			},
+			AccessModes:                   []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+			StorageClassName:              localStorageClassName,
+			VolumeMode:                    &volumeMode,
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				Local: &corev1.LocalVolumeSource{
+					Path: fmt.Sprintf("%s/%s", localStorageMountPath, additionalDisk.Name),
+				},
+			},
+			NodeAffinity: &corev1.VolumeNodeAffinity{
+				Required: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{
+							MatchExpressions: []corev1.NodeSelectorRequirement{
+								{
+									Key:      corev1.LabelHostname,
+									Operator: corev1.NodeSelectorOpIn,
+									Values:   []string{hostname},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func localPVName(diskName string) string {
+	return fmt.Sprintf("crc-local-%s", diskName)
+}
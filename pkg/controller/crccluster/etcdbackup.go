@@ -0,0 +1,387 @@
+package crccluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/bbrowning/crc-operator/pkg/s3"
+	sshClient "github.com/code-ready/machine/libmachine/ssh"
+	"github.com/go-logr/logr"
+	"github.com/operator-framework/operator-sdk/pkg/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Keys of the Secret with the S3-compatible storage etcd backups get
+// uploaded to
+const (
+	etcdBackupEndpointKey  = "endpoint"
+	etcdBackupBucketKey    = "bucket"
+	etcdBackupAccessKeyKey = "accessKeyId"
+	etcdBackupSecretKeyKey = "secretKey"
+	etcdBackupRegionKey    = "region"
+	etcdBackupPrefixKey    = "prefix"
+)
+
+const (
+	defaultEtcdBackupInterval = 24 * time.Hour
+	defaultEtcdBackupRetain   = 7
+	defaultEtcdBackupRegion   = "us-east-1"
+
+	// etcdBackupRetryInterval is how long to wait before trying a
+	// failed backup again
+	etcdBackupRetryInterval = 10 * time.Minute
+
+	// etcdBackupSuffix is the suffix of the object key of each
+	// backup, after the backup's name
+	etcdBackupSuffix = ".tar.gz"
+)
+
+// etcdBackupInterval returns how often to back up the cluster's etcd
+func etcdBackupInterval(crc *crcv1alpha1.CrcCluster) (time.Duration, error) {
+	if crc.Spec.EtcdBackup.Interval == "" {
+		return defaultEtcdBackupInterval, nil
+	}
+	interval, err := time.ParseDuration(crc.Spec.EtcdBackup.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid etcd backup interval: %v", err)
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("Invalid etcd backup interval: %s is not positive", crc.Spec.EtcdBackup.Interval)
+	}
+	return interval, nil
+}
+
+// requeueForEtcdBackup returns how long until the cluster's next etcd
+// backup, or 0 if it doesn't get backed up
+func requeueForEtcdBackup(crc *crcv1alpha1.CrcCluster) time.Duration {
+	if crc.Spec.EtcdBackup == nil {
+		return 0
+	}
+	interval, err := etcdBackupInterval(crc)
+	if err != nil {
+		return 0
+	}
+	backupStatus := crc.Status.EtcdBackup
+	if backupStatus == nil || backupStatus.LastAttemptTime == nil {
+		return time.Second
+	}
+	next := backupStatus.LastAttemptTime.Add(interval)
+	if crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeEtcdBackupFailed) {
+		next = backupStatus.LastAttemptTime.Add(etcdBackupRetryInterval)
+	}
+	if until := time.Until(next); until > 0 {
+		return until
+	}
+	return time.Second
+}
+
+// etcdBackupClient returns a client for the S3-compatible storage in
+// the cluster's etcd backup Secret, along with the prefix of the
+// object keys of the cluster's backups
+func (r *ReconcileCrcCluster) etcdBackupClient(crc *crcv1alpha1.CrcCluster) (*s3.Client, string, error) {
+	secret := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: crc.Spec.EtcdBackup.SecretName, Namespace: crc.Namespace}, secret)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, key := range []string{etcdBackupEndpointKey, etcdBackupBucketKey, etcdBackupAccessKeyKey, etcdBackupSecretKeyKey} {
+		if len(secret.Data[key]) == 0 {
+			return nil, "", fmt.Errorf("Secret %s has no %s", secret.Name, key)
+		}
+	}
+	client := &s3.Client{
+		Endpoint:        string(secret.Data[etcdBackupEndpointKey]),
+		Region:          string(secret.Data[etcdBackupRegionKey]),
+		Bucket:          string(secret.Data[etcdBackupBucketKey]),
+		AccessKeyID:     string(secret.Data[etcdBackupAccessKeyKey]),
+		SecretAccessKey: string(secret.Data[etcdBackupSecretKeyKey]),
+	}
+	if client.Region == "" {
+		client.Region = defaultEtcdBackupRegion
+	}
+	prefix := strings.Trim(string(secret.Data[etcdBackupPrefixKey]), "/")
+	if prefix != "" {
+		prefix = prefix + "/"
+	}
+	return client, fmt.Sprintf("%s%s/%s/", prefix, crc.Namespace, crc.Name), nil
+}
+
+// backupEtcd backs up the etcd of a Ready cluster when its next
+// scheduled backup is due. The node's own backup tooling writes an
+// etcd snapshot along with the static pod resources it goes with,
+// which get uploaded as a single tarball named after the time of the
+// backup. Backups beyond the number to retain get deleted, oldest
+// first. A failed backup doesn't fail the reconcile, it just gets
+// reported in the EtcdBackupFailed condition and tried again later.
+func (r *ReconcileCrcCluster) backupEtcd(logger logr.Logger, crc *crcv1alpha1.CrcCluster, sshClient *sshClient.NativeClient) (*crcv1alpha1.CrcCluster, error) {
+	if crc.Spec.EtcdBackup == nil {
+		return crc, nil
+	}
+	if _, err := etcdBackupInterval(crc); err != nil {
+		if !crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeEtcdBackupFailed) {
+			r.recorder.Event(crc, corev1.EventTypeWarning, "EtcdBackupFailed", err.Error())
+		}
+		crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeEtcdBackupFailed, true, "InvalidInterval", err.Error())
+		return r.updateCrcClusterStatus(crc)
+	}
+	if requeueForEtcdBackup(crc) > time.Second {
+		return crc, nil
+	}
+
+	if crc.Status.EtcdBackup == nil {
+		crc.Status.EtcdBackup = &crcv1alpha1.CrcClusterEtcdBackupStatus{}
+	}
+	now := metav1.Now()
+	crc.Status.EtcdBackup.LastAttemptTime = &now
+
+	backupName := now.UTC().Format("20060102-150405")
+	logger.Info("Backing up etcd.", "Backup", backupName)
+	backups, reason, err := r.uploadEtcdBackup(logger, crc, sshClient, backupName)
+	if err != nil {
+		logger.Error(err, "Failed to back up etcd.", "Backup", backupName)
+		r.recorder.Event(crc, corev1.EventTypeWarning, "EtcdBackupFailed", fmt.Sprintf("Failed to back up etcd: %v", err))
+		crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeEtcdBackupFailed, true, reason, err.Error())
+		return r.updateCrcClusterStatus(crc)
+	}
+
+	crc.Status.EtcdBackup.LastBackupTime = &now
+	crc.Status.EtcdBackup.LastBackup = backupName
+	crc.Status.EtcdBackup.Backups = backups
+	crc.SetConditionBool(crcv1alpha1.ConditionTypeEtcdBackupFailed, false)
+	r.recorder.Event(crc, corev1.EventTypeNormal, "EtcdBackedUp", fmt.Sprintf("Backed up etcd as %s", backupName))
+	return r.updateCrcClusterStatus(crc)
+}
+
+// uploadEtcdBackup backs up etcd inside the cluster and uploads it
+// as backupName, then prunes old backups. It returns the names of the
+// backups left in the bucket, newest first, or the reason it failed.
+func (r *ReconcileCrcCluster) uploadEtcdBackup(logger logr.Logger, crc *crcv1alpha1.CrcCluster, sshClient *sshClient.NativeClient, backupName string) ([]string, status.ConditionReason, error) {
+	client, prefix, err := r.etcdBackupClient(crc)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, "SecretNotFound", fmt.Errorf("Secret %s not found", crc.Spec.EtcdBackup.SecretName)
+		}
+		return nil, "InvalidSecret", err
+	}
+
+	backupScript := `
+set -e
+sudo rm -rf /home/core/etcd-backup /home/core/etcd-backup.tar.gz
+sudo /usr/local/bin/cluster-backup.sh /home/core/etcd-backup
+sudo tar -C /home/core/etcd-backup -czf /home/core/etcd-backup.tar.gz .
+sudo chown core:core /home/core/etcd-backup.tar.gz
+sudo rm -rf /home/core/etcd-backup
+stat -c %s /home/core/etcd-backup.tar.gz
+`
+	output, err := sshQuickOutput(sshClient, backupScript)
+	if err != nil {
+		return nil, "BackupFailed", fmt.Errorf("%v: %s", err, strings.TrimSpace(output))
+	}
+	// The size is the last line, after whatever the backup tooling
+	// printed
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	size, err := strconv.ParseInt(strings.TrimSpace(lines[len(lines)-1]), 10, 64)
+	if err != nil {
+		return nil, "BackupFailed", fmt.Errorf("Unexpected size of etcd backup: %v", err)
+	}
+
+	key := prefix + backupName + etcdBackupSuffix
+	logger.Info("Uploading etcd backup.", "Bucket", client.Bucket, "Key", key, "Size", size)
+	err = sshStreamOutput(sshClient, "cat /home/core/etcd-backup.tar.gz", func(stdout io.Reader) error {
+		return client.PutObject(key, stdout, size)
+	})
+	if _, cleanupErr := sshQuickOutput(sshClient, "rm -f /home/core/etcd-backup.tar.gz"); cleanupErr != nil {
+		logger.Error(cleanupErr, "Failed to clean up etcd backup in the cluster.")
+	}
+	if err != nil {
+		return nil, "UploadFailed", err
+	}
+
+	backups, err := pruneEtcdBackups(logger, crc, client, prefix)
+	if err != nil {
+		return nil, "PruneFailed", err
+	}
+	return backups, "", nil
+}
+
+// pruneEtcdBackups deletes the cluster's oldest backups beyond the
+// number to retain and returns the names of the ones left, newest
+// first
+func pruneEtcdBackups(logger logr.Logger, crc *crcv1alpha1.CrcCluster, client *s3.Client, prefix string) ([]string, error) {
+	objects, err := client.ListObjects(prefix)
+	if err != nil {
+		return nil, err
+	}
+	backups := []string{}
+	for _, object := range objects {
+		name := strings.TrimPrefix(object.Key, prefix)
+		if strings.Contains(name, "/") || !strings.HasSuffix(name, etcdBackupSuffix) {
+			continue
+		}
+		backups = append(backups, strings.TrimSuffix(name, etcdBackupSuffix))
+	}
+	// Backup names are timestamps, so they sort oldest first
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	retain := crc.Spec.EtcdBackup.Retain
+	if retain <= 0 {
+		retain = defaultEtcdBackupRetain
+	}
+	if len(backups) <= retain {
+		return backups, nil
+	}
+	for _, backup := range backups[retain:] {
+		logger.Info("Deleting old etcd backup.", "Backup", backup)
+		if err := client.DeleteObject(prefix + backup + etcdBackupSuffix); err != nil && !s3.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return backups[:retain], nil
+}
+
+// etcdRestoreRequested returns true if the cluster's etcd restore
+// was requested after the last request that was handled. A request
+// from before the cluster was created is ignored, like with resets.
+func etcdRestoreRequested(crc *crcv1alpha1.CrcCluster) bool {
+	if crc.Spec.EtcdBackup == nil || crc.Spec.EtcdBackup.Restore == nil {
+		return false
+	}
+	requestedAt := &crc.Spec.EtcdBackup.Restore.RequestedAt
+	if requestedAt.Before(&crc.CreationTimestamp) {
+		return false
+	}
+	backupStatus := crc.Status.EtcdBackup
+	return backupStatus == nil || backupStatus.LastRestoreRequestedAt == nil || backupStatus.LastRestoreRequestedAt.Before(requestedAt)
+}
+
+// restoreEtcd restores the cluster's etcd in place from the requested
+// backup with the node's own restore tooling. The cluster goes back
+// to its state at the time of the backup, so it gets configured
+// again afterwards to bring back its current credentials, pull
+// secret, and cluster ID. A failed restore gets reported in the
+// EtcdRestoreFailed condition and isn't tried again until it's
+// requested again. It returns true if etcd got restored.
+func (r *ReconcileCrcCluster) restoreEtcd(logger logr.Logger, crc *crcv1alpha1.CrcCluster, sshClient *sshClient.NativeClient) (*crcv1alpha1.CrcCluster, bool, error) {
+	if !etcdRestoreRequested(crc) {
+		return crc, false, nil
+	}
+	restore := crc.Spec.EtcdBackup.Restore
+	if crc.Status.EtcdBackup == nil {
+		crc.Status.EtcdBackup = &crcv1alpha1.CrcClusterEtcdBackupStatus{}
+	}
+	requestedAt := restore.RequestedAt
+	crc.Status.EtcdBackup.LastRestoreRequestedAt = &requestedAt
+
+	logger.Info("Restoring etcd.", "Backup", restore.Backup)
+	r.recorder.Event(crc, corev1.EventTypeNormal, "RestoringEtcd", fmt.Sprintf("Restoring etcd from backup %s", restore.Backup))
+	reason, err := r.downloadEtcdBackup(crc, sshClient, restore.Backup)
+	if err != nil {
+		logger.Error(err, "Failed to restore etcd.", "Backup", restore.Backup)
+		r.recorder.Event(crc, corev1.EventTypeWarning, "EtcdRestoreFailed", fmt.Sprintf("Failed to restore etcd from backup %s: %v", restore.Backup, err))
+		crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeEtcdRestoreFailed, true, reason, err.Error())
+		crc, err = r.updateCrcClusterStatus(crc)
+		return crc, false, err
+	}
+
+	now := metav1.Now()
+	crc.Status.EtcdBackup.LastRestoreTime = &now
+	crc.Status.EtcdBackup.LastRestoredBackup = restore.Backup
+	crc.SetConditionBool(crcv1alpha1.ConditionTypeEtcdRestoreFailed, false)
+	crc.SetConditionBool(crcv1alpha1.ConditionTypeClusterNotConfigured, true)
+	crc.SetConditionBool(crcv1alpha1.ConditionTypeReady, false)
+	r.recorder.Event(crc, corev1.EventTypeNormal, "EtcdRestored", fmt.Sprintf("Restored etcd from backup %s", restore.Backup))
+	crc, err = r.updateCrcClusterStatus(crc)
+	return crc, true, err
+}
+
+// downloadEtcdBackup streams a backup into the cluster and restores
+// it, returning the reason it failed
+func (r *ReconcileCrcCluster) downloadEtcdBackup(crc *crcv1alpha1.CrcCluster, sshClient *sshClient.NativeClient, backupName string) (status.ConditionReason, error) {
+	if backupName == "" || strings.Contains(backupName, "/") {
+		return "InvalidBackup", fmt.Errorf("Invalid backup name %q", backupName)
+	}
+	client, prefix, err := r.etcdBackupClient(crc)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "SecretNotFound", fmt.Errorf("Secret %s not found", crc.Spec.EtcdBackup.SecretName)
+		}
+		return "InvalidSecret", err
+	}
+
+	body, err := client.GetObject(prefix + backupName + etcdBackupSuffix)
+	if err != nil {
+		if s3.IsNotFound(err) {
+			return "BackupNotFound", fmt.Errorf("Backup %s not found in bucket %s", backupName, client.Bucket)
+		}
+		return "DownloadFailed", err
+	}
+	defer body.Close()
+
+	restoreScript := `
+set -e
+sudo rm -rf /home/core/etcd-restore
+mkdir -p /home/core/etcd-restore
+tar -C /home/core/etcd-restore -xzf -
+sudo /usr/local/bin/cluster-restore.sh /home/core/etcd-restore
+sudo systemctl restart kubelet
+sudo rm -rf /home/core/etcd-restore
+`
+	output, err := sshInputOutput(sshClient, restoreScript, body)
+	if err != nil {
+		return "RestoreFailed", fmt.Errorf("%v: %s", err, strings.TrimSpace(output))
+	}
+	return "", nil
+}
+
+// sshStreamOutput runs command and hands its stdout to consume as it
+// comes in, for output too large to buffer
+func sshStreamOutput(client *sshClient.NativeClient, command string, consume func(io.Reader) error) error {
+	conn, session, err := sshQuickSession(client, command)
+	if err != nil {
+		return err
+	}
+	defer sshCloseConn(conn)
+	defer session.Close()
+
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	session.Stderr = &stderr
+	if err := session.Start(command); err != nil {
+		return err
+	}
+	if err := consume(stdout); err != nil {
+		return err
+	}
+	if err := session.Wait(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// sshInputOutput runs command with stdin streamed from input and
+// returns its combined output
+func sshInputOutput(client *sshClient.NativeClient, command string, input io.Reader) (string, error) {
+	conn, session, err := sshQuickSession(client, command)
+	if err != nil {
+		return "", err
+	}
+	defer sshCloseConn(conn)
+	defer session.Close()
+
+	session.Stdin = input
+	output, err := session.CombinedOutput(command)
+	return string(output), err
+}
@@ -0,0 +1,33 @@
+package crccluster
+
+import (
+	"context"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// exportInProgress returns true if the cluster is kept stopped, or
+// is stopping, for a CrcClusterExport
+func exportInProgress(crc *crcv1alpha1.CrcCluster) bool {
+	return crc.Annotations[crcv1alpha1.ExportAnnotation] != ""
+}
+
+// releaseDeletedExport removes the export annotation from a cluster
+// once its CrcClusterExport got deleted so the cluster starts again
+func (r *ReconcileCrcCluster) releaseDeletedExport(logger logr.Logger, crc *crcv1alpha1.CrcCluster) (bool, error) {
+	if !exportInProgress(crc) {
+		return false, nil
+	}
+	exportName := crc.Annotations[crcv1alpha1.ExportAnnotation]
+	export := &crcv1alpha1.CrcClusterExport{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: exportName, Namespace: crc.Namespace}, export)
+	if err == nil || !errors.IsNotFound(err) {
+		return false, err
+	}
+	logger.Info("Removing annotation of deleted CrcClusterExport.", "Export", exportName)
+	delete(crc.Annotations, crcv1alpha1.ExportAnnotation)
+	return true, r.client.Update(context.TODO(), crc)
+}
@@ -0,0 +1,62 @@
+package crccluster
+
+import (
+	"context"
+	"time"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/bbrowning/crc-operator/pkg/policy"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// enforceMaxLifetime sets the cluster's expiration time based on the
+// maximum lifetime of any CrcClusterPolicy that applies to it and
+// deletes the cluster once it expires. It returns true if the
+// cluster was deleted.
+//
+// The lifetime counts from when a maximum lifetime first applied to
+// the cluster rather than from its creation, so adding a policy
+// doesn't delete every older cluster at once. acceptClaim restarts it
+// for clusters claimed from a pool.
+func (r *ReconcileCrcCluster) enforceMaxLifetime(logger logr.Logger, crc *crcv1alpha1.CrcCluster) (*crcv1alpha1.CrcCluster, bool, error) {
+	policies, err := policy.ForNamespace(r.client, crc.Namespace)
+	if err != nil {
+		logger.Error(err, "Failed to get CrcClusterPolicies.")
+		return crc, false, err
+	}
+
+	maxLifetime := policy.MaxLifetime(policies)
+	if maxLifetime == nil {
+		crc.Status.LifetimeStartTime = nil
+		crc.Status.ExpirationTime = nil
+		return crc, false, nil
+	}
+	if crc.Status.LifetimeStartTime == nil {
+		now := metav1.Now().Rfc3339Copy()
+		crc.Status.LifetimeStartTime = &now
+	}
+	expirationTime := metav1.NewTime(crc.Status.LifetimeStartTime.Add(*maxLifetime)).Rfc3339Copy()
+	crc.Status.ExpirationTime = &expirationTime
+	if time.Now().Before(expirationTime.Time) {
+		return crc, false, nil
+	}
+
+	logger.Info("Deleting CrcCluster that reached its maximum lifetime.", "ExpirationTime", expirationTime)
+	if err := r.client.Delete(context.TODO(), crc); err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Failed to delete expired CrcCluster.")
+		return crc, false, err
+	}
+	return crc, true, nil
+}
+
+// requeueForExpiration returns a reconcile.Result that requeues the
+// cluster when it expires, if it has an expiration time
+func requeueForExpiration(crc *crcv1alpha1.CrcCluster) reconcile.Result {
+	if crc.Status.ExpirationTime == nil {
+		return reconcile.Result{}
+	}
+	return reconcile.Result{RequeueAfter: time.Until(crc.Status.ExpirationTime.Time)}
+}
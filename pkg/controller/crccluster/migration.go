@@ -0,0 +1,311 @@
+package crccluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// defaultDrainPolicy is the DrainPolicy of clusters that don't set
+// one
+var defaultDrainPolicy = os.Getenv("DEFAULT_DRAIN_POLICY")
+
+// drainPolicyForCrcCluster returns what happens to a cluster that
+// can't be live migrated when its Node gets drained
+func drainPolicyForCrcCluster(crc *crcv1alpha1.CrcCluster) (crcv1alpha1.CrcClusterDrainPolicy, error) {
+	if crc.Spec.DrainPolicy != "" {
+		return crc.Spec.DrainPolicy, nil
+	}
+	switch policy := crcv1alpha1.CrcClusterDrainPolicy(defaultDrainPolicy); policy {
+	case "":
+		return crcv1alpha1.CrcClusterDrainPolicyRecreate, nil
+	case crcv1alpha1.CrcClusterDrainPolicyBlock, crcv1alpha1.CrcClusterDrainPolicyStop, crcv1alpha1.CrcClusterDrainPolicyRecreate:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("Invalid DEFAULT_DRAIN_POLICY environment variable: %s", defaultDrainPolicy)
+	}
+}
+
+// applyEvictionStrategy makes KubeVirt live migrate a VirtualMachine
+// whose disks are all persistent ReadWriteMany storage instead of
+// shutting it off. Other access modes and emptyDisks can't be live
+// migrated. claimAccessModes has the access modes of
+// PersistentVolumeClaims the VirtualMachine mounts directly, like the
+// one of a cluster restored from a snapshot.
+func applyEvictionStrategy(vm *kubevirtv1.VirtualMachine, claimAccessModes map[string][]corev1.PersistentVolumeAccessMode) {
+	accessModes := map[string][]corev1.PersistentVolumeAccessMode{}
+	for _, dataVolume := range vm.Spec.DataVolumeTemplates {
+		if dataVolume.Spec.PVC != nil {
+			accessModes[dataVolume.Name] = dataVolume.Spec.PVC.AccessModes
+		}
+	}
+	persistent := false
+	for _, volume := range vm.Spec.Template.Spec.Volumes {
+		var volumeAccessModes []corev1.PersistentVolumeAccessMode
+		switch {
+		case volume.EmptyDisk != nil:
+			return
+		case volume.DataVolume != nil:
+			volumeAccessModes = accessModes[volume.DataVolume.Name]
+		case volume.PersistentVolumeClaim != nil:
+			volumeAccessModes = claimAccessModes[volume.PersistentVolumeClaim.ClaimName]
+		default:
+			continue
+		}
+		if !hasAccessMode(volumeAccessModes, corev1.ReadWriteMany) {
+			return
+		}
+		persistent = true
+	}
+	if !persistent {
+		return
+	}
+	evictionStrategy := kubevirtv1.EvictionStrategyLiveMigrate
+	vm.Spec.Template.Spec.EvictionStrategy = &evictionStrategy
+}
+
+func hasAccessMode(accessModes []corev1.PersistentVolumeAccessMode, accessMode corev1.PersistentVolumeAccessMode) bool {
+	for _, mode := range accessModes {
+		if mode == accessMode {
+			return true
+		}
+	}
+	return false
+}
+
+// liveMigrates returns true if the given VirtualMachineInstance gets
+// live migrated off of drained Nodes
+func liveMigrates(vmi *kubevirtv1.VirtualMachineInstance) bool {
+	return vmi.Spec.EvictionStrategy != nil && *vmi.Spec.EvictionStrategy == kubevirtv1.EvictionStrategyLiveMigrate
+}
+
+// ensurePodDisruptionBudgetExists keeps the virt-launcher pod of a
+// cluster from being evicted by a drain, so the operator can migrate,
+// stop, or recreate the cluster according to its DrainPolicy instead
+func (r *ReconcileCrcCluster) ensurePodDisruptionBudgetExists(logger logr.Logger, crc *crcv1alpha1.CrcCluster) error {
+	pdb, err := r.newPodDisruptionBudgetForCrcCluster(crc)
+	if err != nil {
+		logger.Error(err, "Failed to create PodDisruptionBudget.", "PodDisruptionBudget.Namespace", pdb.Namespace, "PodDisruptionBudget.Name", pdb.Name)
+		return err
+	}
+
+	existingPdb := &policyv1beta1.PodDisruptionBudget{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: pdb.Name, Namespace: pdb.Namespace}, existingPdb)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating a new PodDisruptionBudget.", "PodDisruptionBudget.Namespace", pdb.Namespace, "PodDisruptionBudget.Name", pdb.Name)
+		err = r.client.Create(context.TODO(), pdb)
+		if err != nil {
+			logger.Error(err, "Failed to create PodDisruptionBudget.", "PodDisruptionBudget.Namespace", pdb.Namespace, "PodDisruptionBudget.Name", pdb.Name)
+			return err
+		}
+	} else if err != nil {
+		logger.Error(err, "Failed to get PodDisruptionBudget.")
+		return err
+	}
+	return nil
+}
+
+func (r *ReconcileCrcCluster) newPodDisruptionBudgetForCrcCluster(crc *crcv1alpha1.CrcCluster) (*policyv1beta1.PodDisruptionBudget, error) {
+	minAvailable := intstr.FromInt(1)
+	pdb := &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      crc.Name,
+			Namespace: crc.Namespace,
+			Labels: map[string]string{
+				"crcCluster": crc.Name,
+			},
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"crcCluster":         crc.Name,
+					"kubevirt.io/domain": crc.Name,
+				},
+			},
+		},
+	}
+	if err := controllerutil.SetControllerReference(crc, pdb, r.scheme); err != nil {
+		return pdb, err
+	}
+	return pdb, nil
+}
+
+// handleNodeDrain reports where a cluster's VirtualMachine is running
+// and, once its Node is cordoned for a drain, live migrates it or
+// applies the cluster's DrainPolicy. It returns true if the
+// VirtualMachine got stopped or deleted.
+func (r *ReconcileCrcCluster) handleNodeDrain(logger logr.Logger, crc *crcv1alpha1.CrcCluster) (*crcv1alpha1.CrcCluster, bool, error) {
+	vmi := &kubevirtv1.VirtualMachineInstance{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: crc.Name, Namespace: crc.Namespace}, vmi)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			crc.Status.NodeName = ""
+			return crc, false, nil
+		}
+		logger.Error(err, "Failed to get VirtualMachineInstance.")
+		return crc, false, err
+	}
+
+	crc.Status.NodeName = vmi.Status.NodeName
+	crc.Status.LiveMigratable = false
+	for _, condition := range vmi.Status.Conditions {
+		if condition.Type == kubevirtv1.VirtualMachineInstanceIsMigratable && condition.Status == corev1.ConditionTrue {
+			crc.Status.LiveMigratable = true
+		}
+	}
+	if err := r.updateMigrationStatus(crc, vmi); err != nil {
+		logger.Error(err, "Failed to get VirtualMachineInstanceMigration.")
+		return crc, false, err
+	}
+
+	if vmi.Status.Phase != kubevirtv1.Running || vmi.DeletionTimestamp != nil || vmi.Status.NodeName == "" {
+		return crc, false, nil
+	}
+	node := &corev1.Node{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: vmi.Status.NodeName}, node)
+	if err != nil {
+		logger.Error(err, "Failed to get Node.", "Node.Name", vmi.Status.NodeName)
+		return crc, false, err
+	}
+	if !node.Spec.Unschedulable {
+		return crc, false, nil
+	}
+
+	if liveMigrates(vmi) {
+		return crc, false, r.migrate(logger, crc, vmi)
+	}
+
+	policy, err := drainPolicyForCrcCluster(crc)
+	if err != nil {
+		logger.Error(err, "Invalid drain policy.")
+		return crc, false, err
+	}
+	switch policy {
+	case crcv1alpha1.CrcClusterDrainPolicyBlock:
+		logger.Info("Blocking drain of Node.", "Node.Name", node.Name)
+		r.recorder.Eventf(crc, corev1.EventTypeWarning, "DrainBlocked", "Blocking the drain of Node %s until this cluster is stopped or deleted", node.Name)
+		return crc, false, nil
+	case crcv1alpha1.CrcClusterDrainPolicyStop:
+		logger.Info("Stopping cluster for drain of Node.", "Node.Name", node.Name)
+		clusterStatus := crc.Status.DeepCopy()
+		crc.Spec.Stopped = true
+		if err := r.client.Update(context.TODO(), crc); err != nil {
+			logger.Error(err, "Failed to stop CrcCluster.")
+			return crc, false, err
+		}
+		crc.Status = *clusterStatus
+		r.recorder.Eventf(crc, corev1.EventTypeWarning, "StoppedForDrain", "Stopped this cluster because Node %s is being drained, set spec.stopped to false to start it on another Node", node.Name)
+		return crc, true, nil
+	default:
+		logger.Info("Recreating cluster for drain of Node.", "Node.Name", node.Name)
+		if err := r.client.Delete(context.TODO(), vmi); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete VirtualMachineInstance.")
+			return crc, false, err
+		}
+		r.recorder.Eventf(crc, corev1.EventTypeWarning, "RecreatedForDrain", "Recreating this cluster on another Node because Node %s is being drained", node.Name)
+		return crc, true, nil
+	}
+}
+
+// migrate starts a live migration of the cluster's VirtualMachine
+// off of its cordoned Node unless one is already in progress
+func (r *ReconcileCrcCluster) migrate(logger logr.Logger, crc *crcv1alpha1.CrcCluster, vmi *kubevirtv1.VirtualMachineInstance) error {
+	if crc.Status.Migration != nil && !migrationPhaseFinal(crc.Status.Migration.Phase) {
+		return nil
+	}
+	if !crc.Status.LiveMigratable {
+		logger.Info("VirtualMachine can't be live migrated off of its cordoned Node.", "Node.Name", vmi.Status.NodeName)
+		r.recorder.Eventf(crc, corev1.EventTypeWarning, "NotLiveMigratable", "Blocking the drain of Node %s because this cluster can't be live migrated, stop it to let the drain finish", vmi.Status.NodeName)
+		return nil
+	}
+
+	migration := &kubevirtv1.VirtualMachineInstanceMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-migration-", crc.Name),
+			Namespace:    crc.Namespace,
+			Labels: map[string]string{
+				"crcCluster": crc.Name,
+			},
+		},
+		Spec: kubevirtv1.VirtualMachineInstanceMigrationSpec{
+			VMIName: vmi.Name,
+		},
+	}
+	if err := controllerutil.SetControllerReference(crc, migration, r.scheme); err != nil {
+		return err
+	}
+	logger.Info("Live migrating VirtualMachine off of its cordoned Node.", "Node.Name", vmi.Status.NodeName)
+	if err := r.client.Create(context.TODO(), migration); err != nil {
+		logger.Error(err, "Failed to create VirtualMachineInstanceMigration.")
+		return err
+	}
+	now := metav1.Now()
+	crc.Status.Migration = &crcv1alpha1.CrcClusterMigrationStatus{
+		Name:       migration.Name,
+		Phase:      string(kubevirtv1.MigrationPending),
+		SourceNode: vmi.Status.NodeName,
+		StartTime:  &now,
+	}
+	r.recorder.Eventf(crc, corev1.EventTypeNormal, "Migrating", "Live migrating off of Node %s because it's being drained", vmi.Status.NodeName)
+	return nil
+}
+
+// updateMigrationStatus copies the progress of the cluster's latest
+// live migration into its status
+func (r *ReconcileCrcCluster) updateMigrationStatus(crc *crcv1alpha1.CrcCluster, vmi *kubevirtv1.VirtualMachineInstance) error {
+	if crc.Status.Migration == nil || crc.Status.Migration.Name == "" {
+		return nil
+	}
+	previousPhase := crc.Status.Migration.Phase
+	if migrationPhaseFinal(previousPhase) {
+		return nil
+	}
+
+	migration := &kubevirtv1.VirtualMachineInstanceMigration{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: crc.Status.Migration.Name, Namespace: crc.Namespace}, migration)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			crc.Status.Migration.Phase = string(kubevirtv1.MigrationFailed)
+			r.recorder.Eventf(crc, corev1.EventTypeWarning, "MigrationFailed", "VirtualMachineInstanceMigration %s was deleted", crc.Status.Migration.Name)
+			return nil
+		}
+		return err
+	}
+	if migration.Status.Phase != kubevirtv1.MigrationPhaseUnset {
+		crc.Status.Migration.Phase = string(migration.Status.Phase)
+	}
+	if state := vmi.Status.MigrationState; state != nil && state.MigrationUID == migration.UID {
+		crc.Status.Migration.SourceNode = state.SourceNode
+		crc.Status.Migration.TargetNode = state.TargetNode
+		if state.StartTimestamp != nil {
+			crc.Status.Migration.StartTime = state.StartTimestamp
+		}
+		crc.Status.Migration.EndTime = state.EndTimestamp
+	}
+
+	if crc.Status.Migration.Phase != previousPhase {
+		switch migration.Status.Phase {
+		case kubevirtv1.MigrationSucceeded:
+			r.recorder.Eventf(crc, corev1.EventTypeNormal, "MigrationSucceeded", "Live migrated from Node %s to Node %s", crc.Status.Migration.SourceNode, crc.Status.Migration.TargetNode)
+		case kubevirtv1.MigrationFailed:
+			r.recorder.Eventf(crc, corev1.EventTypeWarning, "MigrationFailed", "Live migration from Node %s failed", crc.Status.Migration.SourceNode)
+		}
+	}
+	return nil
+}
+
+func migrationPhaseFinal(phase string) bool {
+	return phase == string(kubevirtv1.MigrationSucceeded) || phase == string(kubevirtv1.MigrationFailed)
+}
@@ -0,0 +1,77 @@
+package crccluster
+
+import (
+	"context"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+)
+
+// kubevirtSubresourcesPath is where KubeVirt serves subresources
+// like pause and restart, which aren't part of its CRDs
+const kubevirtSubresourcesPath = "/apis/subresources.kubevirt.io/v1alpha3"
+
+// ensurePauseState pauses or unpauses the cluster's
+// VirtualMachineInstance to match spec.paused. It returns true if the
+// cluster is paused, or on its way to being paused, and reconciling
+// should stop.
+func (r *ReconcileCrcCluster) ensurePauseState(logger logr.Logger, crc *crcv1alpha1.CrcCluster) (bool, error) {
+	vmi := &kubevirtv1.VirtualMachineInstance{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: crc.Name, Namespace: crc.Namespace}, vmi)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		logger.Error(err, "Failed to get VirtualMachineInstance.")
+		return false, err
+	}
+	// Only running VirtualMachineInstances can be paused
+	if vmi.Status.Phase != kubevirtv1.Running || vmi.DeletionTimestamp != nil {
+		return false, nil
+	}
+
+	paused := vmiPaused(vmi)
+	shouldPause := crc.Spec.Paused && runStrategyForCrcCluster(crc) != kubevirtv1.RunStrategyHalted
+	switch {
+	case shouldPause && !paused:
+		logger.Info("Pausing VirtualMachineInstance.")
+		if err := r.callKubeVirtSubresource("virtualmachineinstances", vmi, "pause"); err != nil {
+			logger.Error(err, "Failed to pause VirtualMachineInstance.")
+			return false, err
+		}
+		r.recorder.Event(crc, corev1.EventTypeNormal, "Paused", "Paused the cluster's VirtualMachine")
+	case !shouldPause && paused:
+		logger.Info("Unpausing VirtualMachineInstance.")
+		if err := r.callKubeVirtSubresource("virtualmachineinstances", vmi, "unpause"); err != nil {
+			logger.Error(err, "Failed to unpause VirtualMachineInstance.")
+			return false, err
+		}
+		r.recorder.Event(crc, corev1.EventTypeNormal, "Unpaused", "Unpaused the cluster's VirtualMachine")
+		return false, nil
+	}
+	return shouldPause, nil
+}
+
+// callKubeVirtSubresource calls one of KubeVirt's subresources, like
+// pause or restart, for the given kind of resource
+func (r *ReconcileCrcCluster) callKubeVirtSubresource(resource string, obj metav1.Object, subresource string) error {
+	return r.restClient.Put().
+		AbsPath(kubevirtSubresourcesPath, "namespaces", obj.GetNamespace(), resource, obj.GetName(), subresource).
+		Do().
+		Error()
+}
+
+// vmiPaused returns true if the given VirtualMachineInstance is paused
+func vmiPaused(vmi *kubevirtv1.VirtualMachineInstance) bool {
+	for _, condition := range vmi.Status.Conditions {
+		if condition.Type == kubevirtv1.VirtualMachineInstancePaused && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
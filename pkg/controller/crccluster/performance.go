@@ -0,0 +1,70 @@
+package crccluster
+
+import (
+	"fmt"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+)
+
+// cpuForCrcCluster returns the CPU topology and tuning of a cluster's
+// VirtualMachine
+func cpuForCrcCluster(crc *crcv1alpha1.CrcCluster) (*kubevirtv1.CPU, error) {
+	performance := crc.Spec.Performance
+	cores := performance.Cores
+	if cores == 0 {
+		cores = 1
+	}
+	threads := performance.Threads
+	if threads == 0 {
+		threads = 1
+	}
+	sockets := performance.Sockets
+	if sockets == 0 {
+		sockets = crc.Spec.CPU / (cores * threads)
+	}
+	if sockets < 1 || sockets*cores*threads != crc.Spec.CPU {
+		return nil, fmt.Errorf("Requested CPU topology of %d sockets, %d cores, and %d threads does not add up to %d CPUs", sockets, cores, threads, crc.Spec.CPU)
+	}
+
+	return &kubevirtv1.CPU{
+		Sockets:               uint32(sockets),
+		Cores:                 uint32(cores),
+		Threads:               uint32(threads),
+		Model:                 performance.CPUModel,
+		DedicatedCPUPlacement: performance.DedicatedCPUs,
+	}, nil
+}
+
+// applyPerformance applies the memory and disk tuning of a cluster
+// to its VirtualMachine template
+func applyPerformance(vmiSpec *kubevirtv1.VirtualMachineInstanceSpec, crc *crcv1alpha1.CrcCluster) {
+	performance := crc.Spec.Performance
+	if performance.HugepageSize != "" && vmiSpec.Domain.Memory != nil {
+		vmiSpec.Domain.Memory.Hugepages = &kubevirtv1.Hugepages{
+			PageSize: performance.HugepageSize,
+		}
+	}
+	if performance.DedicatedIOThread {
+		ioThreadsPolicy := kubevirtv1.IOThreadsPolicyShared
+		vmiSpec.Domain.IOThreadsPolicy = &ioThreadsPolicy
+	}
+	for i := range vmiSpec.Domain.Devices.Disks {
+		disk := &vmiSpec.Domain.Devices.Disks[i]
+		if performance.DedicatedIOThread {
+			dedicatedIOThread := true
+			disk.DedicatedIOThread = &dedicatedIOThread
+		}
+		disk.Cache = kubevirtv1.DriverCache(performance.DiskCache)
+	}
+}
+
+// hugepagesResourceName returns the Node resource backing the memory
+// of a cluster using hugepages, or an empty string if it doesn't
+func hugepagesResourceName(crc *crcv1alpha1.CrcCluster) corev1.ResourceName {
+	if crc.Spec.Performance.HugepageSize == "" {
+		return ""
+	}
+	return corev1.ResourceName(corev1.ResourceHugePagesPrefix + crc.Spec.Performance.HugepageSize)
+}
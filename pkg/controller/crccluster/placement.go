@@ -0,0 +1,118 @@
+package crccluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+)
+
+// defaultPlacement is a JSON-encoded CrcPlacementSpec applied to
+// every cluster, for example to keep all CRC VMs on a dedicated pool
+// of Nodes.
+var defaultPlacement = os.Getenv("DEFAULT_PLACEMENT")
+
+// hostnameTopologyKey is the well-known Node label used to spread
+// clusters across Nodes
+const hostnameTopologyKey = "kubernetes.io/hostname"
+
+// placementForCrcCluster combines the operator-wide default placement
+// with the placement requested by the cluster
+func placementForCrcCluster(crc *crcv1alpha1.CrcCluster) (crcv1alpha1.CrcPlacementSpec, error) {
+	placement := crcv1alpha1.CrcPlacementSpec{}
+	if defaultPlacement != "" {
+		if err := json.Unmarshal([]byte(defaultPlacement), &placement); err != nil {
+			return placement, fmt.Errorf("Invalid DEFAULT_PLACEMENT environment variable: %v", err)
+		}
+	}
+
+	requested := crc.Spec.Placement.DeepCopy()
+	if len(requested.NodeSelector) > 0 && placement.NodeSelector == nil {
+		placement.NodeSelector = map[string]string{}
+	}
+	for key, value := range requested.NodeSelector {
+		placement.NodeSelector[key] = value
+	}
+	placement.Tolerations = append(placement.Tolerations, requested.Tolerations...)
+	if requested.Affinity != nil {
+		placement.Affinity = requested.Affinity
+	}
+	if requested.PriorityClassName != "" {
+		placement.PriorityClassName = requested.PriorityClassName
+	}
+
+	// Prefer not putting two clusters on the same Node, since they'd
+	// compete for the same CPU, memory, and disk. Only the
+	// virt-launcher pods of clusters have both of these labels.
+	if placement.Affinity == nil {
+		placement.Affinity = &corev1.Affinity{}
+	}
+	if placement.Affinity.PodAntiAffinity == nil {
+		placement.Affinity.PodAntiAffinity = &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{
+					Weight: 100,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{
+							MatchExpressions: []metav1.LabelSelectorRequirement{
+								{
+									Key:      "crcCluster",
+									Operator: metav1.LabelSelectorOpExists,
+								},
+								{
+									Key:      "kubevirt.io/domain",
+									Operator: metav1.LabelSelectorOpExists,
+								},
+							},
+						},
+						TopologyKey: hostnameTopologyKey,
+					},
+				},
+			},
+		}
+	}
+
+	return placement, nil
+}
+
+// applyPlacement sets the scheduling fields of a VirtualMachine
+// template from the given placement
+func applyPlacement(vmiSpec *kubevirtv1.VirtualMachineInstanceSpec, placement crcv1alpha1.CrcPlacementSpec) {
+	vmiSpec.NodeSelector = placement.NodeSelector
+	vmiSpec.Tolerations = placement.Tolerations
+	vmiSpec.Affinity = placement.Affinity
+	vmiSpec.PriorityClassName = placement.PriorityClassName
+}
+
+// nodeMatchesPlacement returns true if the given Node matches the
+// placement's node selector and all of the Node's NoSchedule and
+// NoExecute taints are tolerated. Node affinity is left for the
+// scheduler to sort out.
+func nodeMatchesPlacement(node *corev1.Node, placement crcv1alpha1.CrcPlacementSpec) bool {
+	for key, value := range placement.NodeSelector {
+		if node.Labels[key] != value {
+			return false
+		}
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range placement.Tolerations {
+			if placement.Tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package crccluster
+
+import (
+	"context"
+	"time"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+)
+
+// runStrategyForCrcCluster returns the RunStrategy of a cluster's
+// VirtualMachine. Clusters being snapshotted stay halted until the
+// snapshot is taken.
+func runStrategyForCrcCluster(crc *crcv1alpha1.CrcCluster) kubevirtv1.VirtualMachineRunStrategy {
+	if crc.Spec.Stopped || snapshotInProgress(crc) || exportInProgress(crc) {
+		return kubevirtv1.RunStrategyHalted
+	}
+	if crc.Spec.RunStrategy != "" {
+		return kubevirtv1.VirtualMachineRunStrategy(crc.Spec.RunStrategy)
+	}
+	return kubevirtv1.RunStrategyAlways
+}
+
+// vmHalted returns true if the given VirtualMachine is meant to be
+// stopped. This handles VirtualMachines created before RunStrategy
+// was used, which set Running instead.
+func vmHalted(vm *kubevirtv1.VirtualMachine) bool {
+	runStrategy, err := vm.RunStrategy()
+	if err != nil {
+		return false
+	}
+	return runStrategy == kubevirtv1.RunStrategyHalted
+}
+
+// updatePowerState sets the cluster's power state from the actual
+// phase of its VirtualMachineInstance and records how long it took to
+// start or stop
+func (r *ReconcileCrcCluster) updatePowerState(vm *kubevirtv1.VirtualMachine, crc *crcv1alpha1.CrcCluster) error {
+	vmi := &kubevirtv1.VirtualMachineInstance{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: vm.Name, Namespace: vm.Namespace}, vmi)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		vmi = nil
+	}
+
+	powerState := powerStateForVMI(vm, vmi, crc.Status.PowerState)
+	if powerState != crc.Status.PowerState {
+		now := metav1.Now()
+		if crc.Status.PowerStateTransitionTime != nil {
+			spent := &metav1.Duration{Duration: now.Sub(crc.Status.PowerStateTransitionTime.Time).Round(time.Second)}
+			if crc.Status.PowerState == crcv1alpha1.CrcClusterPowerStateStarting && powerState == crcv1alpha1.CrcClusterPowerStateRunning {
+				crc.Status.LastStartDuration = spent
+			}
+			if crc.Status.PowerState == crcv1alpha1.CrcClusterPowerStateStopping && powerState == crcv1alpha1.CrcClusterPowerStateStopped {
+				crc.Status.LastStopDuration = spent
+			}
+		}
+		crc.Status.PowerState = powerState
+		crc.Status.PowerStateTransitionTime = &now
+	}
+	crc.Status.Stopped = powerState == crcv1alpha1.CrcClusterPowerStateStopped
+	return nil
+}
+
+// powerStateForVMI returns the power state of a VirtualMachine given
+// its VirtualMachineInstance, or nil if there is none, and the
+// previous power state
+func powerStateForVMI(vm *kubevirtv1.VirtualMachine, vmi *kubevirtv1.VirtualMachineInstance, previous crcv1alpha1.CrcClusterPowerState) crcv1alpha1.CrcClusterPowerState {
+	runStrategy, err := vm.RunStrategy()
+	if err != nil {
+		return previous
+	}
+	halted := runStrategy == kubevirtv1.RunStrategyHalted
+
+	if vmi == nil {
+		if halted || runStrategy == kubevirtv1.RunStrategyManual {
+			return crcv1alpha1.CrcClusterPowerStateStopped
+		}
+		// KubeVirt hasn't created the VirtualMachineInstance yet
+		return crcv1alpha1.CrcClusterPowerStateStarting
+	}
+	if vmi.DeletionTimestamp != nil {
+		return crcv1alpha1.CrcClusterPowerStateStopping
+	}
+
+	switch vmi.Status.Phase {
+	case kubevirtv1.VmPhaseUnset, kubevirtv1.Pending, kubevirtv1.Scheduling, kubevirtv1.Scheduled:
+		if halted {
+			return crcv1alpha1.CrcClusterPowerStateStopping
+		}
+		return crcv1alpha1.CrcClusterPowerStateStarting
+	case kubevirtv1.Running:
+		if halted {
+			return crcv1alpha1.CrcClusterPowerStateStopping
+		}
+		if vmiPaused(vmi) {
+			return crcv1alpha1.CrcClusterPowerStatePaused
+		}
+		return crcv1alpha1.CrcClusterPowerStateRunning
+	case kubevirtv1.Succeeded:
+		if runStrategy == kubevirtv1.RunStrategyAlways {
+			// KubeVirt will start a new VirtualMachineInstance
+			return crcv1alpha1.CrcClusterPowerStateStarting
+		}
+		return crcv1alpha1.CrcClusterPowerStateStopped
+	case kubevirtv1.Failed:
+		if runStrategy == kubevirtv1.RunStrategyAlways || runStrategy == kubevirtv1.RunStrategyRerunOnFailure {
+			return crcv1alpha1.CrcClusterPowerStateStarting
+		}
+		return crcv1alpha1.CrcClusterPowerStateStopped
+	default:
+		return previous
+	}
+}
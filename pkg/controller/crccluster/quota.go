@@ -0,0 +1,88 @@
+package crccluster
+
+import (
+	"context"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/bbrowning/crc-operator/pkg/policy"
+	"github.com/bbrowning/crc-operator/pkg/quota"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+)
+
+// existingVirtualMachine returns the cluster's VirtualMachine, or nil
+// if it doesn't exist yet
+func (r *ReconcileCrcCluster) existingVirtualMachine(logger logr.Logger, crc *crcv1alpha1.CrcCluster) (*kubevirtv1.VirtualMachine, error) {
+	vm := &kubevirtv1.VirtualMachine{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: crc.Name, Namespace: crc.Namespace}, vm)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		logger.Error(err, "Failed to get VirtualMachine.")
+		return nil, err
+	}
+	return vm, nil
+}
+
+// enforcePolicy checks whether the cluster violates any
+// CrcClusterPolicy when its VirtualMachine is about to be created or
+// started and updates the PolicyViolation condition. It returns false
+// if the cluster must wait until it no longer violates a policy.
+//
+// Like quotas, policies only hold back new and stopped clusters.
+// Running clusters keep getting reconciled when a policy gets
+// tightened, so they can still be stopped, paused, or deleted.
+func (r *ReconcileCrcCluster) enforcePolicy(logger logr.Logger, crc *crcv1alpha1.CrcCluster, existingVirtualMachine *kubevirtv1.VirtualMachine) (*crcv1alpha1.CrcCluster, bool, error) {
+	if !needsAdmission(crc, existingVirtualMachine) {
+		crc.SetConditionBool(crcv1alpha1.ConditionTypePolicyViolation, false)
+		return crc, true, nil
+	}
+
+	if err := policy.Check(r.client, crc); err != nil {
+		if !policy.IsViolation(err) {
+			logger.Error(err, "Failed to check CrcClusterPolicies.")
+			return crc, false, err
+		}
+		logger.Info("CrcCluster violates a CrcClusterPolicy.", "Reason", err.Error())
+		crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypePolicyViolation, true, "PolicyViolation", err.Error())
+		crc, err = r.updateCrcClusterStatus(crc)
+		return crc, false, err
+	}
+
+	crc.SetConditionBool(crcv1alpha1.ConditionTypePolicyViolation, false)
+	return crc, true, nil
+}
+
+// enforceQuota checks whether creating or starting the VirtualMachine
+// for this cluster would exceed any CrcClusterQuota and updates the
+// QuotaExceeded condition. It returns false if the cluster must wait
+// until enough quota is available.
+//
+// The admission webhook rejects most requests over quota up front,
+// but this catches clusters created while the webhook wasn't running
+// and quotas that were lowered after a stopped cluster was admitted.
+func (r *ReconcileCrcCluster) enforceQuota(logger logr.Logger, crc *crcv1alpha1.CrcCluster, existingVirtualMachine *kubevirtv1.VirtualMachine) (*crcv1alpha1.CrcCluster, bool, error) {
+	// Only creating a new VirtualMachine or starting a stopped one
+	// needs more quota
+	if !needsAdmission(crc, existingVirtualMachine) {
+		crc.SetConditionBool(crcv1alpha1.ConditionTypeQuotaExceeded, false)
+		return crc, true, nil
+	}
+
+	if err := quota.Check(r.client, crc); err != nil {
+		if !quota.IsExceeded(err) {
+			logger.Error(err, "Failed to check CrcClusterQuotas.")
+			return crc, false, err
+		}
+		logger.Info("Waiting on CrcClusterQuota to allow this cluster.", "Reason", err.Error())
+		crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeQuotaExceeded, true, "QuotaExceeded", err.Error())
+		crc, err = r.updateCrcClusterStatus(crc)
+		return crc, false, err
+	}
+
+	crc.SetConditionBool(crcv1alpha1.ConditionTypeQuotaExceeded, false)
+	return crc, true, nil
+}
@@ -0,0 +1,137 @@
+package crccluster
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	sshClient "github.com/code-ready/machine/libmachine/ssh"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxClockSkew is how far the clock inside a cluster may drift from
+// the operator's before it gets corrected
+const maxClockSkew = time.Minute
+
+// kubeletCerts are the kubelet's certificates, which expire and
+// aren't renewed while a cluster is stopped
+var kubeletCerts = []string{
+	"/var/lib/kubelet/pki/kubelet-client-current.pem",
+	"/var/lib/kubelet/pki/kubelet-server-current.pem",
+}
+
+// prepareCertificateRecovery runs right after a cluster boots and
+// before kubelet starts. It corrects the clock, which drifts while a
+// cluster is stopped, and removes any expired kubelet certificates so
+// kubelet bootstraps new ones. Recovery finishes in
+// recoverCertificates once the API server is up.
+func (r *ReconcileCrcCluster) prepareCertificateRecovery(logger logr.Logger, sshClient *sshClient.NativeClient, crc *crcv1alpha1.CrcCluster) (*crcv1alpha1.CrcCluster, error) {
+	output, err := sshQuickOutput(sshClient, "date +%s")
+	if err != nil {
+		logger.Error(err, "Error reading clock in VirtualMachine.", "Output", output)
+		return crc, err
+	}
+	vmSeconds, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return crc, fmt.Errorf("Unexpected output from date in VirtualMachine: %s", output)
+	}
+	now := time.Now()
+	skew := time.Unix(vmSeconds, 0).Sub(now)
+	if skew > maxClockSkew || skew < -maxClockSkew {
+		logger.Info("Correcting clock skew in VirtualMachine.", "Skew", skew.String())
+		output, err := sshQuickOutput(sshClient, fmt.Sprintf("sudo date -s @%d", now.Unix()))
+		if err != nil {
+			logger.Error(err, "Error correcting clock in VirtualMachine.", "Output", output)
+			return crc, err
+		}
+		crc.Status.LastClockSkew = &metav1.Duration{Duration: skew.Round(time.Second)}
+	}
+
+	expiredCerts := []string{}
+	for _, cert := range kubeletCerts {
+		output, err := sshQuickOutput(sshClient, fmt.Sprintf(`if sudo test -e %[1]s && ! sudo openssl x509 -checkend 0 -noout -in %[1]s; then echo "__cert_expired: true"; fi`, cert))
+		if err != nil {
+			logger.Error(err, "Error checking certificate in VirtualMachine.", "Certificate", cert, "Output", output)
+			return crc, err
+		}
+		if strings.Contains(output, "__cert_expired: true") {
+			expiredCerts = append(expiredCerts, cert)
+		}
+	}
+	if len(expiredCerts) == 0 {
+		return crc, nil
+	}
+
+	logger.Info("Removing expired kubelet certificates so new ones get issued.", "Certificates", expiredCerts)
+	removeScript := fmt.Sprintf(`
+set -e
+sudo rm -f %s
+if sudo systemctl is-active kubelet; then sudo systemctl restart kubelet; fi
+`, strings.Join(expiredCerts, " "))
+	output, err = sshQuickOutput(sshClient, removeScript)
+	if err != nil {
+		logger.Error(err, "Error removing expired kubelet certificates.", "Output", output)
+		return crc, err
+	}
+	crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeCertificatesExpired, true, "KubeletCertificatesExpired", fmt.Sprintf("Expired certificates %s were removed, waiting on new ones", strings.Join(expiredCerts, ", ")))
+	return r.updateCrcClusterStatus(crc)
+}
+
+// recoverCertificates waits, while CSRs keep getting approved, until
+// every Node in the cluster is Ready again after coming up with
+// expired certificates. It returns false while recovery is still in
+// progress.
+func (r *ReconcileCrcCluster) recoverCertificates(logger logr.Logger, clusterHost string, crc *crcv1alpha1.CrcCluster, k8sClient *kubernetes.Clientset) (*crcv1alpha1.CrcCluster, bool, error) {
+	// OpenShift regenerates the API server's certificates itself
+	// once kubelet is running again
+	if apiServerCertExpired(clusterHost) {
+		crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeCertificatesExpired, true, "APIServerCertificateExpired", "Waiting on OpenShift to regenerate the expired API server certificate")
+	}
+	if !crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeCertificatesExpired) {
+		return crc, true, nil
+	}
+
+	nodes, err := k8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		// The API server may not be able to authenticate us yet
+		logger.Info("Unable to list Nodes while recovering from expired certificates.", "Error", err.Error())
+		crc, err = r.updateCrcClusterStatus(crc)
+		return crc, false, err
+	}
+	for _, node := range nodes.Items {
+		if !nodeReady(&node) {
+			crc, err = r.updateCrcClusterStatus(crc)
+			return crc, false, err
+		}
+	}
+	if apiServerCertExpired(clusterHost) {
+		crc, err = r.updateCrcClusterStatus(crc)
+		return crc, false, err
+	}
+
+	logger.Info("Recovered from expired certificates.")
+	now := metav1.Now()
+	crc.Status.LastCertificateRecoveryTime = &now
+	crc.SetConditionBool(crcv1alpha1.ConditionTypeCertificatesExpired, false)
+	crc, err = r.updateCrcClusterStatus(crc)
+	return crc, err == nil, err
+}
+
+// apiServerCertExpired returns true if the cluster's API server is
+// serving an expired certificate
+func apiServerCertExpired(host string) bool {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(host, strconv.Itoa(apiServerPort)), &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+	certs := conn.ConnectionState().PeerCertificates
+	return len(certs) > 0 && time.Now().After(certs[0].NotAfter)
+}
@@ -0,0 +1,114 @@
+package crccluster
+
+import (
+	"context"
+	"fmt"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/operator-framework/operator-sdk/pkg/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resetRequested returns true if the cluster's spec.resetRequestedAt
+// is after its last reset. A request from before the cluster was
+// created, like one left in a manifest that got applied again, is
+// ignored.
+func resetRequested(crc *crcv1alpha1.CrcCluster) bool {
+	if crc.Spec.ResetRequestedAt == nil || crc.Spec.ResetRequestedAt.Before(&crc.CreationTimestamp) {
+		return false
+	}
+	return crc.Status.LastResetTime == nil || crc.Status.LastResetTime.Before(crc.Spec.ResetRequestedAt)
+}
+
+// resetCluster gives a cluster a factory reset. Its VirtualMachine
+// gets deleted along with its DataVolumes, and any volume restored
+// from a snapshot, and once they're all gone its status gets cleared
+// down to what it keeps across the reset so the VirtualMachine gets
+// recreated from the bundle and fully configured again like a new
+// cluster. It returns true while the reset is in progress.
+func (r *ReconcileCrcCluster) resetCluster(logger logr.Logger, crc *crcv1alpha1.CrcCluster) (*crcv1alpha1.CrcCluster, bool, error) {
+	if !resetRequested(crc) {
+		return crc, false, nil
+	}
+
+	if !crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeResetInProgress) {
+		logger.Info("Starting factory reset of CrcCluster.", "ResetRequestedAt", crc.Spec.ResetRequestedAt)
+		r.recorder.Event(crc, corev1.EventTypeNormal, "Resetting", "Recreating the cluster from its bundle")
+		crc.SetConditionBool(crcv1alpha1.ConditionTypeResetInProgress, true)
+		crc.SetConditionBool(crcv1alpha1.ConditionTypeReady, false)
+		crc, err := r.updateCrcClusterStatus(crc)
+		return crc, true, err
+	}
+
+	gone, err := r.deleteForReset(logger, crc, &kubevirtv1.VirtualMachine{}, crc.Name)
+	if err != nil || !gone {
+		return crc, true, err
+	}
+	if crc.Spec.Storage.Persistent {
+		gone, err = r.deleteForReset(logger, crc, &cdiv1.DataVolume{}, crcv1alpha1.RootVolumeName(crc.Name))
+		if err != nil || !gone {
+			return crc, true, err
+		}
+		gone, err = r.deleteForReset(logger, crc, &corev1.PersistentVolumeClaim{}, crcv1alpha1.RootVolumeName(crc.Name))
+		if err != nil || !gone {
+			return crc, true, err
+		}
+	}
+
+	now := metav1.Now()
+	crc.Status = crcv1alpha1.CrcClusterStatus{
+		BaseDomain:         crc.Status.BaseDomain,
+		APIURL:             crc.Status.APIURL,
+		ConsoleURL:         crc.Status.ConsoleURL,
+		ClusterID:          crc.Status.ClusterID,
+		KubeAdminClientKey: crc.Status.KubeAdminClientKey,
+		KubeAdminPassword:  crc.Status.KubeAdminPassword,
+		Claim:              crc.Status.Claim,
+		LifetimeStartTime:  crc.Status.LifetimeStartTime,
+		ExpirationTime:     crc.Status.ExpirationTime,
+		LastResetTime:      &now,
+		EtcdBackup:         crc.Status.EtcdBackup,
+		Conditions:         status.Conditions{},
+	}
+	logger.Info("Finished factory reset of CrcCluster, recreating it.")
+	r.recorder.Event(crc, corev1.EventTypeNormal, "Reset", fmt.Sprintf("Reset the cluster as requested at %s", crc.Spec.ResetRequestedAt.UTC()))
+	crc, err = r.updateCrcClusterStatus(crc)
+	return crc, true, err
+}
+
+// deleteForReset deletes an object belonging to a cluster being
+// reset, in the cluster's namespace, and returns true once it's gone.
+// Dependents get deleted first so the VirtualMachine only disappears
+// after its VirtualMachineInstance and DataVolumes.
+func (r *ReconcileCrcCluster) deleteForReset(logger logr.Logger, crc *crcv1alpha1.CrcCluster, obj runtime.Object, name string) (bool, error) {
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: crc.Namespace}, obj)
+	if errors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return false, err
+	}
+	if objMeta.GetDeletionTimestamp() != nil {
+		logger.Info("Waiting on deletion for factory reset.", "Name", name)
+		return false, nil
+	}
+	logger.Info("Deleting for factory reset.", "Name", name)
+	err = r.client.Delete(context.TODO(), obj, client.PropagationPolicy(metav1.DeletePropagationForeground))
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	return false, nil
+}
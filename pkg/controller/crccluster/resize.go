@@ -0,0 +1,309 @@
+package crccluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	sshClient "github.com/code-ready/machine/libmachine/ssh"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+)
+
+// resizeRestartDelay is how long users get warned before a cluster's
+// VirtualMachine gets restarted to apply a resize
+var resizeRestartDelay = os.Getenv("RESIZE_RESTART_DELAY")
+
+const defaultResizeRestartDelay = 5 * time.Minute
+
+// Reasons of the ResizePending condition
+const (
+	resizeReasonExpandingVolume = "ExpandingVolume"
+	resizeReasonRestartPending  = "RestartPending"
+	resizeReasonRestarting      = "Restarting"
+	resizeReasonResized         = "Resized"
+)
+
+// resize applies changes to the CPU, memory, or storage of a running
+// cluster. Persistent volumes get expanded in place. The
+// VirtualMachine gets restarted, after warning about it, so it picks
+// up the new CPU, memory, or disk size. The filesystem gets grown to
+// fill the disk in finishResize after the restart.
+func (r *ReconcileCrcCluster) resize(logger logr.Logger, crc *crcv1alpha1.CrcCluster, vm *kubevirtv1.VirtualMachine) (*crcv1alpha1.CrcCluster, error) {
+	vmi := &kubevirtv1.VirtualMachineInstance{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: vm.Name, Namespace: vm.Namespace}, vmi)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return crc, nil
+		}
+		logger.Error(err, "Failed to get VirtualMachineInstance.")
+		return crc, err
+	}
+	if vmi.Status.Phase != kubevirtv1.Running || vmi.DeletionTimestamp != nil {
+		return crc, nil
+	}
+
+	reason := resizeReason(crc)
+	if reason == resizeReasonRestarting {
+		// Wait for finishResize once the new VirtualMachineInstance
+		// is up
+		return crc, nil
+	}
+
+	changes := vmiChanges(vm, vmi)
+	if crc.Spec.Storage.Persistent {
+		expanded, err := r.expandVolume(logger, crc, vm)
+		if err != nil {
+			return crc, err
+		}
+		if !expanded {
+			return crc, nil
+		}
+		if reason == resizeReasonExpandingVolume || hasResizeChange(crc, "storage") {
+			changes = append(changes, "storage")
+		}
+	}
+	if len(changes) == 0 {
+		if reason == resizeReasonRestartPending {
+			// Whatever needed the restart was reverted
+			crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeResizePending, false, resizeReasonResized, "Nothing left to resize")
+			crc.Status.ResizeRestartTime = nil
+			crc.Status.ResizeChanges = nil
+		}
+		return crc, nil
+	}
+
+	delay, err := resizeRestartDelayForCrcCluster()
+	if err != nil {
+		logger.Error(err, "Invalid resize restart delay.")
+		return crc, err
+	}
+	changed := strings.Join(changes, ", ")
+	if reason != resizeReasonRestartPending || crc.Status.ResizeRestartTime == nil || !reflect.DeepEqual(changes, crc.Status.ResizeChanges) {
+		restartTime := metav1.NewTime(time.Now().Add(delay))
+		crc.Status.ResizeRestartTime = &restartTime
+		crc.Status.ResizeChanges = changes
+		message := fmt.Sprintf("The cluster will be restarted at %s to apply its new %s", restartTime.UTC().Format(time.RFC3339), changed)
+		if !crc.Spec.Storage.Persistent {
+			message = message + ", losing everything in it because its storage is ephemeral"
+		}
+		logger.Info("Scheduling a restart of the VirtualMachine to apply a resize.", "Changes", changed, "RestartTime", restartTime)
+		crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeResizePending, true, resizeReasonRestartPending, message)
+		r.recorder.Event(crc, corev1.EventTypeWarning, "RestartPending", message)
+		return crc, nil
+	}
+	if time.Now().Before(crc.Status.ResizeRestartTime.Time) {
+		return crc, nil
+	}
+
+	logger.Info("Restarting VirtualMachine to apply a resize.", "Changes", changed)
+	if err := r.callKubeVirtSubresource("virtualmachines", vm, "restart"); err != nil {
+		logger.Error(err, "Failed to restart VirtualMachine.")
+		return crc, err
+	}
+	crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeResizePending, true, resizeReasonRestarting, fmt.Sprintf("Restarting to apply the new %s", changed))
+	r.recorder.Event(crc, corev1.EventTypeNormal, "Restarting", fmt.Sprintf("Restarting to apply the new %s", changed))
+	return crc, nil
+}
+
+// finishResize grows the root filesystem to fill the disk once the
+// VirtualMachine restarted for a resize
+func (r *ReconcileCrcCluster) finishResize(logger logr.Logger, sshClient *sshClient.NativeClient, crc *crcv1alpha1.CrcCluster) (*crcv1alpha1.CrcCluster, error) {
+	if resizeReason(crc) != resizeReasonRestarting {
+		return crc, nil
+	}
+	vmi := &kubevirtv1.VirtualMachineInstance{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: crc.Name, Namespace: crc.Namespace}, vmi)
+	if err != nil {
+		logger.Error(err, "Failed to get VirtualMachineInstance.")
+		return crc, err
+	}
+	if crc.Status.ResizeRestartTime != nil && vmi.CreationTimestamp.Before(crc.Status.ResizeRestartTime) {
+		// Still the VirtualMachineInstance from before the restart
+		return crc, nil
+	}
+
+	logger.Info("Growing root filesystem after resize.")
+	output, err := sshQuickOutput(sshClient, "sudo xfs_growfs /")
+	if err != nil {
+		logger.Error(err, "Error growing root filesystem.", "Output", output)
+		return crc, err
+	}
+	crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeResizePending, false, resizeReasonResized, "The cluster was restarted with its new size")
+	crc.Status.ResizeRestartTime = nil
+	crc.Status.ResizeChanges = nil
+	r.recorder.Event(crc, corev1.EventTypeNormal, "Resized", "The cluster was restarted with its new size")
+	return r.updateCrcClusterStatus(crc)
+}
+
+// expandVolume requests more space for the cluster's persistent
+// volume if its storage size grew. It returns false while the volume
+// is still expanding.
+func (r *ReconcileCrcCluster) expandVolume(logger logr.Logger, crc *crcv1alpha1.CrcCluster, vm *kubevirtv1.VirtualMachine) (bool, error) {
+	desired, found, err := desiredRootVolumeSize(crc, vm)
+	if err != nil || !found {
+		return err == nil, err
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: crcv1alpha1.RootVolumeName(crc.Name), Namespace: vm.Namespace}, pvc)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		logger.Error(err, "Failed to get PersistentVolumeClaim.")
+		return false, err
+	}
+
+	requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if requested.Cmp(desired) < 0 {
+		logger.Info("Expanding PersistentVolumeClaim.", "From", requested.String(), "To", desired.String())
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = desired
+		if err := r.client.Update(context.TODO(), pvc); err != nil {
+			if errors.IsForbidden(err) || errors.IsInvalid(err) {
+				// Most likely the StorageClass doesn't allow expansion
+				message := fmt.Sprintf("Unable to expand the persistent volume: %v", err)
+				crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeResizePending, true, resizeReasonExpandingVolume, message)
+				r.recorder.Event(crc, corev1.EventTypeWarning, "VolumeExpansionFailed", message)
+				return false, nil
+			}
+			logger.Error(err, "Failed to expand PersistentVolumeClaim.")
+			return false, err
+		}
+		message := fmt.Sprintf("Expanding the persistent volume from %s to %s", requested.String(), desired.String())
+		crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeResizePending, true, resizeReasonExpandingVolume, message)
+		r.recorder.Event(crc, corev1.EventTypeNormal, "ExpandingVolume", message)
+		return false, nil
+	}
+	if resizeReason(crc) != resizeReasonExpandingVolume {
+		return true, nil
+	}
+	capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+	return capacity.Cmp(desired) >= 0, nil
+}
+
+// desiredRootVolumeSize returns the size the cluster's root volume
+// should have, whether it's a DataVolume or a PersistentVolumeClaim
+// restored from a snapshot, or false if it has neither
+func desiredRootVolumeSize(crc *crcv1alpha1.CrcCluster, vm *kubevirtv1.VirtualMachine) (resource.Quantity, bool, error) {
+	if dataVolume := rootDataVolumeTemplate(vm); dataVolume != nil {
+		return dataVolume.Spec.PVC.Resources.Requests[corev1.ResourceStorage], true, nil
+	}
+	rootVolume := vm.Spec.Template.Spec.Volumes[0]
+	if rootVolume.PersistentVolumeClaim == nil || rootVolume.PersistentVolumeClaim.ClaimName != crcv1alpha1.RootVolumeName(crc.Name) {
+		return resource.Quantity{}, false, nil
+	}
+	// A restored volume starts out at least as large as its snapshot,
+	// so only a requested storage size can grow it
+	if crc.Spec.Storage.Size == "" {
+		return resource.Quantity{}, false, nil
+	}
+	desired, err := resource.ParseQuantity(crc.Spec.Storage.Size)
+	if err != nil {
+		return resource.Quantity{}, false, err
+	}
+	return desired, true, nil
+}
+
+// vmiChanges returns whether the running VirtualMachineInstance has
+// a different number of CPUs, amount of memory, or number of disks
+// than its VirtualMachine
+func vmiChanges(vm *kubevirtv1.VirtualMachine, vmi *kubevirtv1.VirtualMachineInstance) []string {
+	changes := []string{}
+	desired := vm.Spec.Template.Spec.Domain
+	running := vmi.Spec.Domain
+	if cpuCount(desired.CPU) != cpuCount(running.CPU) {
+		changes = append(changes, "CPU")
+	}
+	if desired.Memory != nil && desired.Memory.Guest != nil && running.Memory != nil && running.Memory.Guest != nil {
+		if desired.Memory.Guest.Cmp(*running.Memory.Guest) != 0 {
+			changes = append(changes, "memory")
+		}
+	}
+	if len(desired.Devices.Disks) != len(running.Devices.Disks) ||
+		!reflect.DeepEqual(emptyDiskCapacities(vm.Spec.Template.Spec.Volumes), emptyDiskCapacities(vmi.Spec.Volumes)) {
+		changes = append(changes, "disks")
+	}
+	return changes
+}
+
+// emptyDiskCapacities maps the names of emptyDisk volumes to their
+// capacity
+func emptyDiskCapacities(volumes []kubevirtv1.Volume) map[string]string {
+	capacities := map[string]string{}
+	for _, volume := range volumes {
+		if volume.EmptyDisk != nil {
+			capacities[volume.Name] = volume.EmptyDisk.Capacity.String()
+		}
+	}
+	return capacities
+}
+
+func cpuCount(cpu *kubevirtv1.CPU) uint32 {
+	if cpu == nil {
+		return 0
+	}
+	count := uint32(1)
+	for _, n := range []uint32{cpu.Sockets, cpu.Cores, cpu.Threads} {
+		if n > 0 {
+			count *= n
+		}
+	}
+	return count
+}
+
+func resizeReason(crc *crcv1alpha1.CrcCluster) string {
+	condition := crc.Status.Conditions.GetCondition(crcv1alpha1.ConditionTypeResizePending)
+	if condition == nil || condition.Status != corev1.ConditionTrue {
+		return ""
+	}
+	return string(condition.Reason)
+}
+
+func hasResizeChange(crc *crcv1alpha1.CrcCluster, change string) bool {
+	for _, pending := range crc.Status.ResizeChanges {
+		if pending == change {
+			return true
+		}
+	}
+	return false
+}
+
+// requeueForResize returns how long until a pending resize needs
+// attention again, or 0 if none is pending
+func requeueForResize(crc *crcv1alpha1.CrcCluster) time.Duration {
+	switch resizeReason(crc) {
+	case resizeReasonExpandingVolume:
+		return 30 * time.Second
+	case resizeReasonRestartPending:
+		if crc.Status.ResizeRestartTime != nil {
+			if until := time.Until(crc.Status.ResizeRestartTime.Time); until > 0 {
+				return until
+			}
+		}
+		return time.Second
+	}
+	return 0
+}
+
+// resizeRestartDelayForCrcCluster returns how long to warn before
+// restarting a cluster to apply a resize
+func resizeRestartDelayForCrcCluster() (time.Duration, error) {
+	if resizeRestartDelay != "" {
+		delay, err := time.ParseDuration(resizeRestartDelay)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid RESIZE_RESTART_DELAY environment variable: %v", err)
+		}
+		return delay, nil
+	}
+	return defaultResizeRestartDelay, nil
+}
@@ -0,0 +1,110 @@
+package crccluster
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+)
+
+// Operator-wide overcommit defaults, which individual bundles can
+// override
+var cpuOvercommitRatio = os.Getenv("CPU_OVERCOMMIT_RATIO")
+var memoryOvercommitRatio = os.Getenv("MEMORY_OVERCOMMIT_RATIO")
+var overcommitGuestOverhead = os.Getenv("OVERCOMMIT_GUEST_OVERHEAD")
+
+const (
+	defaultCPUOvercommitRatio    string = "2"
+	defaultMemoryOvercommitRatio string = "1"
+	minRequestCPUMillis          int64  = 2000
+)
+
+// vmResourcesForCrcCluster returns the resources the VirtualMachine
+// of a cluster requests from its Node, after applying the overcommit
+// settings of its bundle or the operator-wide defaults
+func vmResourcesForCrcCluster(crc *crcv1alpha1.CrcCluster, bundle *crcv1alpha1.CrcBundle) (kubevirtv1.ResourceRequirements, error) {
+	resources := kubevirtv1.ResourceRequirements{}
+
+	cpuRatio, err := overcommitRatio(bundle.Spec.Overcommit.CPURatio, cpuOvercommitRatio, defaultCPUOvercommitRatio)
+	if err != nil {
+		return resources, fmt.Errorf("Invalid CPU overcommit ratio: %v", err)
+	}
+	memoryRatio, err := overcommitRatio(bundle.Spec.Overcommit.MemoryRatio, memoryOvercommitRatio, defaultMemoryOvercommitRatio)
+	if err != nil {
+		return resources, fmt.Errorf("Invalid memory overcommit ratio: %v", err)
+	}
+
+	guestOverhead := true
+	if overcommitGuestOverhead != "" {
+		guestOverhead, err = strconv.ParseBool(overcommitGuestOverhead)
+		if err != nil {
+			return resources, fmt.Errorf("Invalid OVERCOMMIT_GUEST_OVERHEAD environment variable: %v", err)
+		}
+	}
+	if bundle.Spec.Overcommit.GuestOverhead != nil {
+		guestOverhead = *bundle.Spec.Overcommit.GuestOverhead
+	}
+	resources.OvercommitGuestOverhead = guestOverhead
+
+	guestMemory, err := resource.ParseQuantity(crc.Spec.Memory)
+	if err != nil {
+		return resources, err
+	}
+	requestMemory := guestMemory
+	if memoryRatio != 1 {
+		requestMemory = *resource.NewQuantity(int64(float64(guestMemory.Value())/memoryRatio), resource.BinarySI)
+	}
+
+	requestCPUMillis := int64(float64(crc.Spec.CPU*1000) / cpuRatio)
+	if requestCPUMillis < minRequestCPUMillis {
+		requestCPUMillis = minRequestCPUMillis
+	}
+	requestCPU := *resource.NewMilliQuantity(requestCPUMillis, resource.DecimalSI)
+	if requestCPUMillis%1000 == 0 {
+		requestCPU = *resource.NewQuantity(requestCPUMillis/1000, resource.DecimalSI)
+	}
+
+	// Memory backed by hugepages can't be overcommitted
+	if crc.Spec.Performance.HugepageSize != "" {
+		requestMemory = guestMemory
+	}
+
+	resources.Requests = corev1.ResourceList{
+		corev1.ResourceCPU:    requestCPU,
+		corev1.ResourceMemory: requestMemory,
+	}
+
+	// Dedicated CPUs need the Guaranteed QoS class, so everything
+	// gets requested up front without any overcommit
+	if crc.Spec.Performance.DedicatedCPUs {
+		resources.OvercommitGuestOverhead = false
+		resources.Requests = corev1.ResourceList{
+			corev1.ResourceCPU:    *resource.NewQuantity(int64(crc.Spec.CPU), resource.DecimalSI),
+			corev1.ResourceMemory: guestMemory,
+		}
+		resources.Limits = resources.Requests.DeepCopy()
+	}
+	return resources, nil
+}
+
+// overcommitRatio parses the first non-empty of the given ratios
+func overcommitRatio(ratios ...string) (float64, error) {
+	for _, ratio := range ratios {
+		if ratio == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(ratio, 64)
+		if err != nil {
+			return 0, err
+		}
+		if value <= 0 {
+			return 0, fmt.Errorf("%s must be greater than 0", ratio)
+		}
+		return value, nil
+	}
+	return 1, nil
+}
@@ -0,0 +1,110 @@
+package crccluster
+
+import (
+	"fmt"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	sshClient "github.com/code-ready/machine/libmachine/ssh"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+)
+
+// scratchDiskName is the name, and serial number, of the emptyDisk
+// that /var of an ephemeral cluster gets relocated onto
+const scratchDiskName = "scratch"
+
+// applyScratchDisk attaches an emptyDisk of the requested storage
+// size to the VirtualMachine of an ephemeral cluster that asked for
+// more space than its bundle's containerDisk has. Being an emptyDisk,
+// it gets wiped along with the containerDisk when the cluster stops.
+func applyScratchDisk(vm *kubevirtv1.VirtualMachine, crc *crcv1alpha1.CrcCluster, bundle *crcv1alpha1.CrcBundle) error {
+	if crc.Spec.Storage.Persistent || crc.Spec.Storage.Size == "" {
+		return nil
+	}
+	bundleQuantity, err := resource.ParseQuantity(bundle.Spec.DiskSize)
+	if err != nil {
+		return err
+	}
+	storageQuantity, err := resource.ParseQuantity(crc.Spec.Storage.Size)
+	if err != nil {
+		return err
+	}
+	if storageQuantity.Cmp(bundleQuantity) < 0 {
+		return fmt.Errorf("Requested storage size %s is less than the minimum disk size of %s needed by bundle %s", crc.Spec.Storage.Size, bundle.Spec.DiskSize, bundle.Name)
+	}
+	if storageQuantity.Cmp(bundleQuantity) == 0 {
+		return nil
+	}
+
+	vmiSpec := &vm.Spec.Template.Spec
+	vmiSpec.Domain.Devices.Disks = append(vmiSpec.Domain.Devices.Disks, kubevirtv1.Disk{
+		Name:   scratchDiskName,
+		Serial: scratchDiskName,
+		DiskDevice: kubevirtv1.DiskDevice{
+			Disk: &kubevirtv1.DiskTarget{
+				Bus: "virtio",
+			},
+		},
+	})
+	vmiSpec.Volumes = append(vmiSpec.Volumes, kubevirtv1.Volume{
+		Name: scratchDiskName,
+		VolumeSource: kubevirtv1.VolumeSource{
+			EmptyDisk: &kubevirtv1.EmptyDiskSource{
+				Capacity: storageQuantity,
+			},
+		},
+	})
+	return nil
+}
+
+// relocateVarToScratchDisk moves /var, where the container images,
+// pod volumes, and etcd live, onto the scratch disk of an ephemeral
+// cluster. This has to happen before the Kubelet gets started. A
+// freshly created scratch disk gets formatted and /var copied onto
+// it, while one that survived a reboot of the VirtualMachine is just
+// mounted again. The Kubelet and CRI-O get stopped so nothing is
+// left running from the old /var.
+func (r *ReconcileCrcCluster) relocateVarToScratchDisk(logger logr.Logger, sshClient *sshClient.NativeClient, vm *kubevirtv1.VirtualMachine) error {
+	if !hasScratchDisk(vm) {
+		return nil
+	}
+	logger.Info("Relocating /var to scratch disk.")
+	output, err := sshQuickOutput(sshClient, relocateVarScript)
+	if err != nil {
+		logger.Error(err, "Error relocating /var to scratch disk.", "Output", output)
+		return err
+	}
+	return nil
+}
+
+var relocateVarScript = fmt.Sprintf(`set -e
+sudo udevadm settle
+DEVICE=$(readlink -f /dev/disk/by-id/virtio-%[1]s)
+if [ "$(findmnt -n -o SOURCE /var)" = "$DEVICE" ]; then
+  exit 0
+fi
+sudo systemctl stop kubelet crio
+if ! sudo blkid $DEVICE; then
+  sudo mkfs.xfs -L %[1]s $DEVICE
+  sudo mkdir -p /run/crc-%[1]s
+  sudo mount $DEVICE /run/crc-%[1]s
+  sudo cp -ax /var/. /run/crc-%[1]s/
+  sudo umount /run/crc-%[1]s
+fi
+sudo mount $DEVICE /var
+sudo restorecon /var
+sudo systemctl restart systemd-journald
+sudo systemctl start crio
+`, scratchDiskName)
+
+// hasScratchDisk returns true if the given VirtualMachine has a
+// scratch disk attached
+func hasScratchDisk(vm *kubevirtv1.VirtualMachine) bool {
+	for _, volume := range vm.Spec.Template.Spec.Volumes {
+		if volume.Name == scratchDiskName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package crccluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	sshClient "github.com/code-ready/machine/libmachine/ssh"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+)
+
+// shutdownGracePeriod is the default for how long to wait on a
+// graceful shutdown before stopping a VirtualMachine anyway
+var shutdownGracePeriod = os.Getenv("SHUTDOWN_GRACE_PERIOD")
+
+const defaultShutdownGracePeriod = 5 * time.Minute
+
+// shutdownSteps are the steps of a graceful shutdown, in order
+var shutdownSteps = []crcv1alpha1.CrcClusterShutdownStep{
+	crcv1alpha1.CrcClusterShutdownStepCordoning,
+	crcv1alpha1.CrcClusterShutdownStepStoppingKubelet,
+	crcv1alpha1.CrcClusterShutdownStepStoppingContainers,
+	crcv1alpha1.CrcClusterShutdownStepSyncingFilesystems,
+	crcv1alpha1.CrcClusterShutdownStepHalting,
+}
+
+// shutdownGracefully shuts down OpenShift inside a cluster with
+// persistent storage before its VirtualMachine gets stopped, so etcd
+// doesn't get corrupted and pods don't get stuck terminating on the
+// next boot. It returns false until the VirtualMachine can be
+// stopped.
+func (r *ReconcileCrcCluster) shutdownGracefully(logger logr.Logger, crc *crcv1alpha1.CrcCluster, bundle *crcv1alpha1.CrcBundle) (*crcv1alpha1.CrcCluster, bool, error) {
+	if runStrategyForCrcCluster(crc) != kubevirtv1.RunStrategyHalted {
+		if crc.Status.ShutdownStep != "" {
+			// The cluster got started again before it finished
+			// shutting down, and may still be running with its
+			// Nodes cordoned and kubelet stopped. Start kubelet and
+			// check the configuration again, just like after a
+			// boot, which also uncordons the Nodes.
+			logger.Info("Graceful shutdown aborted, starting the cluster again.", "ShutdownStep", crc.Status.ShutdownStep)
+			crc.SetConditionBool(crcv1alpha1.ConditionTypeKubeletNotReady, true)
+			crc.SetConditionBool(crcv1alpha1.ConditionTypeClusterNotConfigured, true)
+			crc.SetConditionBool(crcv1alpha1.ConditionTypeReady, false)
+		}
+		crc.Status.ShutdownStep = ""
+		crc.Status.ShutdownStartTime = nil
+		return crc, true, nil
+	}
+	if crc.Status.ShutdownStep == crcv1alpha1.CrcClusterShutdownStepHalting {
+		return crc, true, nil
+	}
+
+	// Only running clusters with persistent storage and a unique SSH
+	// key have anything worth shutting down
+	vm := &kubevirtv1.VirtualMachine{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: crc.Name, Namespace: crc.Namespace}, vm)
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Failed to get VirtualMachine.")
+		return crc, false, err
+	}
+	if errors.IsNotFound(err) || vmHalted(vm) || !vm.Status.Ready || !crc.Spec.Storage.Persistent || crc.Status.SSHKey == "" {
+		crc.Status.ShutdownStep = crcv1alpha1.CrcClusterShutdownStepHalting
+		return crc, true, nil
+	}
+
+	gracePeriod, err := shutdownGracePeriodForCrcCluster(crc)
+	if err != nil {
+		logger.Error(err, "Invalid shutdown grace period.")
+		return crc, false, err
+	}
+	if crc.Status.ShutdownStartTime == nil {
+		now := metav1.Now()
+		crc.Status.ShutdownStartTime = &now
+	}
+	if time.Since(crc.Status.ShutdownStartTime.Time) > gracePeriod {
+		logger.Info("Graceful shutdown took too long, stopping the VirtualMachine anyway.", "ShutdownStep", crc.Status.ShutdownStep)
+		crc.Status.ShutdownStep = crcv1alpha1.CrcClusterShutdownStepHalting
+		return crc, true, nil
+	}
+
+	k8sService, err := r.ensureServiceExists(logger, crc)
+	if err != nil {
+		return crc, false, err
+	}
+	clusterSSHClient, err := createSSHClient(k8sService, crc.Status.SSHKey)
+	if err != nil {
+		logger.Error(err, "Failed to create SSH Client.")
+		return crc, false, err
+	}
+
+	first := 0
+	for i, step := range shutdownSteps {
+		if step == crc.Status.ShutdownStep {
+			first = i
+		}
+	}
+	for _, step := range shutdownSteps[first:] {
+		logger.Info("Shutting down cluster gracefully.", "ShutdownStep", step)
+		crc.Status.ShutdownStep = step
+		crc, err = r.updateCrcClusterStatus(crc)
+		if err != nil {
+			return crc, false, err
+		}
+
+		switch step {
+		case crcv1alpha1.CrcClusterShutdownStepCordoning:
+			err = cordonNodes(crc, bundle)
+		case crcv1alpha1.CrcClusterShutdownStepStoppingKubelet:
+			err = sshShutdownStep(clusterSSHClient, "sudo systemctl stop kubelet")
+		case crcv1alpha1.CrcClusterShutdownStepStoppingContainers:
+			err = sshShutdownStep(clusterSSHClient, "sudo crictl ps -q | xargs -r sudo crictl stop --timeout 30")
+		case crcv1alpha1.CrcClusterShutdownStepSyncingFilesystems:
+			err = sshShutdownStep(clusterSSHClient, "sudo sync")
+		}
+		if err != nil {
+			logger.Error(err, "Failed to shut down cluster gracefully.", "ShutdownStep", step)
+			return crc, false, err
+		}
+	}
+	return crc, true, nil
+}
+
+// shutdownGracePeriodForCrcCluster returns how long the cluster may
+// take to shut down gracefully
+func shutdownGracePeriodForCrcCluster(crc *crcv1alpha1.CrcCluster) (time.Duration, error) {
+	if crc.Spec.ShutdownGracePeriod != nil {
+		return crc.Spec.ShutdownGracePeriod.Duration, nil
+	}
+	if shutdownGracePeriod != "" {
+		gracePeriod, err := time.ParseDuration(shutdownGracePeriod)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid SHUTDOWN_GRACE_PERIOD environment variable: %v", err)
+		}
+		return gracePeriod, nil
+	}
+	return defaultShutdownGracePeriod, nil
+}
+
+func sshShutdownStep(client *sshClient.NativeClient, command string) error {
+	output, err := sshQuickOutput(client, command)
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, output)
+	}
+	return nil
+}
+
+// cordonNodes marks all Nodes inside the cluster as unschedulable.
+// They get marked schedulable again when the cluster gets configured
+// after its next boot.
+func cordonNodes(crc *crcv1alpha1.CrcCluster, bundle *crcv1alpha1.CrcBundle) error {
+	crcK8sConfig, err := restConfigFromCrcCluster(crc, bundle)
+	if err != nil {
+		return err
+	}
+	insecureCrcK8sConfig := rest.CopyConfig(crcK8sConfig)
+	insecureCrcK8sConfig.Insecure = true
+	insecureCrcK8sConfig.CAData = []byte{}
+	k8sClient, err := kubernetes.NewForConfig(insecureCrcK8sConfig)
+	if err != nil {
+		return err
+	}
+	return setNodesUnschedulable(k8sClient, true)
+}
+
+func setNodesUnschedulable(k8sClient *kubernetes.Clientset, unschedulable bool) error {
+	nodes, err := k8sClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable == unschedulable {
+			continue
+		}
+		updatedNode := node.DeepCopy()
+		updatedNode.Spec.Unschedulable = unschedulable
+		if _, err := k8sClient.CoreV1().Nodes().Update(updatedNode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
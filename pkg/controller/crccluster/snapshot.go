@@ -0,0 +1,151 @@
+package crccluster
+
+import (
+	"context"
+	"fmt"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// snapshotInProgress returns true if the cluster is stopped, or
+// stopping, for a CrcClusterSnapshot
+func snapshotInProgress(crc *crcv1alpha1.CrcCluster) bool {
+	return crc.Annotations[crcv1alpha1.SnapshotAnnotation] != ""
+}
+
+// releaseDeletedSnapshot removes the snapshot annotation from a
+// cluster if its CrcClusterSnapshot got deleted before it was done,
+// so the cluster doesn't stay stopped forever
+func (r *ReconcileCrcCluster) releaseDeletedSnapshot(logger logr.Logger, crc *crcv1alpha1.CrcCluster) (bool, error) {
+	if !snapshotInProgress(crc) {
+		return false, nil
+	}
+	snapshotName := crc.Annotations[crcv1alpha1.SnapshotAnnotation]
+	snapshot := &crcv1alpha1.CrcClusterSnapshot{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: snapshotName, Namespace: crc.Namespace}, snapshot)
+	if err == nil || !errors.IsNotFound(err) {
+		return false, err
+	}
+	logger.Info("Removing annotation of deleted CrcClusterSnapshot.", "Snapshot", snapshotName)
+	delete(crc.Annotations, crcv1alpha1.SnapshotAnnotation)
+	return true, r.client.Update(context.TODO(), crc)
+}
+
+// restoresSnapshot returns true if the cluster's persistent volume
+// gets restored from a CrcClusterSnapshot
+func restoresSnapshot(crc *crcv1alpha1.CrcCluster) bool {
+	return createsFromSource(crc) && crc.Spec.Source.Snapshot != ""
+}
+
+// restoreSnapshot prepares a new cluster to be created from a
+// CrcClusterSnapshot. The cluster takes over the snapshotted
+// cluster's bundle, SSH key, credentials, and cluster ID, which are
+// all already in place inside the restored disk, so configuring it
+// only has to adopt its own name and URLs. Its persistent volume
+// gets restored from the snapshot's VolumeSnapshot.
+func (r *ReconcileCrcCluster) restoreSnapshot(logger logr.Logger, crc *crcv1alpha1.CrcCluster) (*crcv1alpha1.CrcCluster, bool, error) {
+	snapshotName := crc.Spec.Source.Snapshot
+	snapshot := &crcv1alpha1.CrcClusterSnapshot{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: snapshotName, Namespace: crc.Namespace}, snapshot)
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Failed to get CrcClusterSnapshot.")
+		return crc, false, err
+	}
+	if errors.IsNotFound(err) || snapshot.Status.Phase != crcv1alpha1.CrcClusterSnapshotPhaseReady {
+		logger.Info("Waiting on CrcClusterSnapshot to be Ready.", "Snapshot", snapshotName)
+		return r.waitOnSource(crc, "SnapshotNotReady", fmt.Sprintf("Waiting on CrcClusterSnapshot %s to be Ready", snapshotName))
+	}
+	if crc.Spec.BundleName != "" && crc.Spec.BundleName != snapshot.Status.BundleName {
+		return r.waitOnSource(crc, "BundleMismatch", fmt.Sprintf("CrcClusterSnapshot %s was taken from bundle %s, not %s", snapshotName, snapshot.Status.BundleName, crc.Spec.BundleName))
+	}
+
+	// The restored disk only works with the bundle it came from
+	if crc.Spec.BundleName == "" {
+		logger.Info("Updating CrcCluster bundle from its snapshot.", "Bundle.Name", snapshot.Status.BundleName)
+		crc.Spec.BundleName = snapshot.Status.BundleName
+		if crc.Spec.BundleImage == "" {
+			crc.Spec.BundleImage = snapshot.Status.BundleImage
+		}
+		return crc, false, r.client.Update(context.TODO(), crc)
+	}
+
+	if err := r.ensureRestoredVolumeExists(logger, crc, snapshot); err != nil {
+		return crc, false, err
+	}
+
+	crc.Status.SSHKey = snapshot.Status.SSHKey
+	crc.Status.ClusterID = snapshot.Status.ClusterID
+	crc.Status.KubeAdminPassword = snapshot.Status.KubeAdminPassword
+	crc.Status.KubeAdminClientKey = snapshot.Status.KubeAdminClientKey
+	setConfigStep(crc, configStepAdminUser)
+	setConfigStep(crc, configStepClusterID)
+	message := fmt.Sprintf("Restored from CrcClusterSnapshot %s", snapshotName)
+	crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeSourceNotReady, false, "Restored", message)
+	r.recorder.Event(crc, corev1.EventTypeNormal, "Restored", message)
+	crc, err = r.updateCrcClusterStatus(crc)
+	return crc, err == nil, err
+}
+
+// ensureRestoredVolumeExists creates the cluster's persistent volume
+// claim from the VolumeSnapshot of a CrcClusterSnapshot. It gets the
+// name the cluster's DataVolume would have had.
+func (r *ReconcileCrcCluster) ensureRestoredVolumeExists(logger logr.Logger, crc *crcv1alpha1.CrcCluster, snapshot *crcv1alpha1.CrcClusterSnapshot) error {
+	var storageQuantity resource.Quantity
+	var err error
+	if snapshot.Status.RestoreSize != "" {
+		storageQuantity, err = resource.ParseQuantity(snapshot.Status.RestoreSize)
+		if err != nil {
+			return err
+		}
+	}
+	if crc.Spec.Storage.Size != "" {
+		requested, err := resource.ParseQuantity(crc.Spec.Storage.Size)
+		if err != nil {
+			return err
+		}
+		if requested.Cmp(storageQuantity) > 0 {
+			storageQuantity = requested
+		}
+	}
+	if storageQuantity.IsZero() {
+		return fmt.Errorf("Unable to tell the size of CrcClusterSnapshot %s", snapshot.Name)
+	}
+
+	pvcSpec, err := pvcSpecForCrcCluster(crc, storageQuantity)
+	if err != nil {
+		return err
+	}
+	apiGroup := "snapshot.storage.k8s.io"
+	pvcSpec.DataSource = &corev1.TypedLocalObjectReference{
+		APIGroup: &apiGroup,
+		Kind:     "VolumeSnapshot",
+		Name:     snapshot.Status.VolumeSnapshotName,
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      crcv1alpha1.RootVolumeName(crc.Name),
+			Namespace: crc.Namespace,
+			Labels: map[string]string{
+				"crcCluster": crc.Name,
+			},
+		},
+		Spec: *pvcSpec,
+	}
+	if err := controllerutil.SetControllerReference(crc, pvc, r.scheme); err != nil {
+		return err
+	}
+
+	logger.Info("Creating PersistentVolumeClaim from VolumeSnapshot.", "PersistentVolumeClaim.Name", pvc.Name, "VolumeSnapshot.Name", snapshot.Status.VolumeSnapshotName)
+	if err := r.client.Create(context.TODO(), pvc); err != nil && !errors.IsAlreadyExists(err) {
+		logger.Error(err, "Failed to create PersistentVolumeClaim.")
+		return err
+	}
+	return nil
+}
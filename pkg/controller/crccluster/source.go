@@ -0,0 +1,302 @@
+package crccluster
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/bbrowning/crc-operator/pkg/bundle"
+	"github.com/bbrowning/crc-operator/pkg/export"
+	"github.com/go-logr/logr"
+	"github.com/operator-framework/operator-sdk/pkg/status"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+)
+
+// prepareSource gets a new cluster with a source ready to be created,
+// either by restoring a CrcClusterSnapshot, cloning another cluster's
+// persistent volume, or importing an exported cluster. It returns false until the cluster can
+// be created.
+func (r *ReconcileCrcCluster) prepareSource(logger logr.Logger, crc *crcv1alpha1.CrcCluster) (*crcv1alpha1.CrcCluster, bool, error) {
+	source := crc.Spec.Source
+	if !createsFromSource(crc) || !crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeSourceNotReady) {
+		return crc, true, nil
+	}
+
+	sources := 0
+	if source.Snapshot != "" {
+		sources++
+	}
+	if source.CrcCluster != "" {
+		sources++
+	}
+	if source.PersistentVolumeClaim != nil {
+		sources++
+	}
+	if source.Import != nil {
+		sources++
+	}
+	if sources != 1 {
+		return r.waitOnSource(crc, "InvalidSource", "Exactly one of snapshot, crcCluster, persistentVolumeClaim, or import has to be set as the source")
+	}
+	if !crc.Spec.Storage.Persistent {
+		return r.waitOnSource(crc, "NotPersistent", "Only clusters with persistent storage can be created from a source")
+	}
+
+	switch {
+	case source.Snapshot != "":
+		return r.restoreSnapshot(logger, crc)
+	case source.CrcCluster != "":
+		return r.prepareClusterClone(logger, crc)
+	case source.Import != nil:
+		return r.prepareImport(logger, crc)
+	default:
+		return r.preparePVCClone(logger, crc)
+	}
+}
+
+// createsFromSource returns true if the cluster's persistent volume
+// comes from its source instead of its bundle. A cluster that got a
+// factory reset always starts over from its bundle.
+func createsFromSource(crc *crcv1alpha1.CrcCluster) bool {
+	return crc.Spec.Source != nil && crc.Status.LastResetTime == nil
+}
+
+// waitOnSource records why a cluster's source isn't ready yet
+func (r *ReconcileCrcCluster) waitOnSource(crc *crcv1alpha1.CrcCluster, reason status.ConditionReason, message string) (*crcv1alpha1.CrcCluster, bool, error) {
+	crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeSourceNotReady, true, reason, message)
+	crc, err := r.updateCrcClusterStatus(crc)
+	return crc, false, err
+}
+
+// prepareClusterClone waits for the CrcCluster to clone to be stopped
+// and takes over its bundle, and its storage size if none was given,
+// since the cloned disk only works with the bundle it came from and
+// can't be smaller than the original. The source cluster's SSH key
+// gets copied so the operator can log in to the clone until it gets
+// a key of its own.
+func (r *ReconcileCrcCluster) prepareClusterClone(logger logr.Logger, crc *crcv1alpha1.CrcCluster) (*crcv1alpha1.CrcCluster, bool, error) {
+	sourceName := crc.Spec.Source.CrcCluster
+	if sourceName == crc.Name {
+		return r.waitOnSource(crc, "InvalidSource", "A CrcCluster can't be cloned from itself")
+	}
+	source := &crcv1alpha1.CrcCluster{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: sourceName, Namespace: crc.Namespace}, source)
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Failed to get source CrcCluster.")
+		return crc, false, err
+	}
+	if errors.IsNotFound(err) {
+		return r.waitOnSource(crc, "SourceNotFound", fmt.Sprintf("Waiting on CrcCluster %s to exist", sourceName))
+	}
+	if !source.Spec.Storage.Persistent {
+		return r.waitOnSource(crc, "NotPersistent", fmt.Sprintf("CrcCluster %s doesn't have persistent storage to clone", sourceName))
+	}
+	if source.Status.SSHKey == "" {
+		return r.waitOnSource(crc, "SourceNotProvisioned", fmt.Sprintf("Waiting on CrcCluster %s to be provisioned", sourceName))
+	}
+	if source.Status.PowerState != crcv1alpha1.CrcClusterPowerStateStopped {
+		return r.waitOnSource(crc, "SourceRunning", fmt.Sprintf("Waiting on CrcCluster %s to be stopped", sourceName))
+	}
+
+	sourceBundle, _, err := bundle.ForCrcCluster(r.client, source)
+	if err != nil {
+		return crc, false, err
+	}
+	if crc.Spec.BundleName != "" && crc.Spec.BundleName != sourceBundle.Name {
+		return r.waitOnSource(crc, "BundleMismatch", fmt.Sprintf("CrcCluster %s was created from bundle %s, not %s", sourceName, sourceBundle.Name, crc.Spec.BundleName))
+	}
+	if crc.Spec.Storage.Size != "" && source.Spec.Storage.Size != "" {
+		requested, err := resource.ParseQuantity(crc.Spec.Storage.Size)
+		if err != nil {
+			return crc, false, err
+		}
+		sourceSize, err := resource.ParseQuantity(source.Spec.Storage.Size)
+		if err != nil {
+			return crc, false, err
+		}
+		if requested.Cmp(sourceSize) < 0 {
+			return r.waitOnSource(crc, "StorageTooSmall", fmt.Sprintf("Requested storage size %s is less than the storage size of %s of CrcCluster %s", crc.Spec.Storage.Size, source.Spec.Storage.Size, sourceName))
+		}
+	}
+
+	// The cloned disk only works with the bundle it came from
+	if crc.Spec.BundleName == "" || (crc.Spec.Storage.Size == "" && source.Spec.Storage.Size != "") {
+		logger.Info("Updating CrcCluster bundle and storage size from its source.", "Bundle.Name", sourceBundle.Name, "Storage.Size", source.Spec.Storage.Size)
+		crc.Spec.BundleName = sourceBundle.Name
+		if crc.Spec.BundleImage == "" {
+			crc.Spec.BundleImage = source.Spec.BundleImage
+		}
+		if crc.Spec.Storage.Size == "" {
+			crc.Spec.Storage.Size = source.Spec.Storage.Size
+		}
+		return crc, false, r.client.Update(context.TODO(), crc)
+	}
+
+	crc.Status.SSHKey = source.Status.SSHKey
+	return r.markCloned(crc, fmt.Sprintf("Cloned from CrcCluster %s", sourceName))
+}
+
+// preparePVCClone takes over the SSH key of the disk to clone from
+// its Secret, if it has one, so the operator can log in to the clone
+// until it gets a key of its own. Without one the disk has to still
+// accept the bundle's SSH key. The operator clones with its own
+// cluster-wide permissions, so a claim in another namespace only gets
+// cloned if the admission webhooks checked the requesting user could
+// clone it themselves.
+func (r *ReconcileCrcCluster) preparePVCClone(logger logr.Logger, crc *crcv1alpha1.CrcCluster) (*crcv1alpha1.CrcCluster, bool, error) {
+	source := crc.Spec.Source.PersistentVolumeClaim
+	if source.Namespace != "" && source.Namespace != crc.Namespace && !AdmissionWebhooksEnabled {
+		return r.waitOnSource(crc, "InvalidSource", fmt.Sprintf("Cloning persistent volume claims from namespace %s needs the operator's admission webhooks to check access to them", source.Namespace))
+	}
+	if source.SSHKeySecretName != "" {
+		secret := &corev1.Secret{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: source.SSHKeySecretName, Namespace: crc.Namespace}, secret)
+		if err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to get SSH key Secret.")
+			return crc, false, err
+		}
+		if errors.IsNotFound(err) {
+			return r.waitOnSource(crc, "SourceNotFound", fmt.Sprintf("Waiting on Secret %s to exist", source.SSHKeySecretName))
+		}
+		sshKey := secret.Data[corev1.SSHAuthPrivateKey]
+		if _, err := ssh.ParsePrivateKey(sshKey); err != nil {
+			return r.waitOnSource(crc, "InvalidSource", fmt.Sprintf("Secret %s has no valid %s: %v", source.SSHKeySecretName, corev1.SSHAuthPrivateKey, err))
+		}
+		crc.Status.SSHKey = base64.StdEncoding.EncodeToString(sshKey)
+	}
+	return r.markCloned(crc, fmt.Sprintf("Cloned from PersistentVolumeClaim %s", source.Name))
+}
+
+// markCloned lets a cloned cluster get created and has its
+// credentials rotated once it's configured, so it doesn't share any
+// with the cluster it was cloned from
+func (r *ReconcileCrcCluster) markCloned(crc *crcv1alpha1.CrcCluster, message string) (*crcv1alpha1.CrcCluster, bool, error) {
+	crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeCredentialsNotRotated, true, "Cloned", "")
+	crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeSourceNotReady, false, "Cloned", message)
+	r.recorder.Event(crc, corev1.EventTypeNormal, "Cloned", message)
+	crc, err := r.updateCrcClusterStatus(crc)
+	return crc, err == nil, err
+}
+
+// prepareImport takes over the bundle, credentials, SSH key, and
+// cluster ID of an exported cluster from the manifest in the import's
+// Secret, like restoring a snapshot, since they're all already in
+// place inside the exported disk. Its storage size defaults to the
+// size of the exported disk and can't be any smaller.
+func (r *ReconcileCrcCluster) prepareImport(logger logr.Logger, crc *crcv1alpha1.CrcCluster) (*crcv1alpha1.CrcCluster, bool, error) {
+	secretName := crc.Spec.Source.Import.SecretName
+	secret := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: crc.Namespace}, secret)
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Failed to get import Secret.")
+		return crc, false, err
+	}
+	if errors.IsNotFound(err) {
+		return r.waitOnSource(crc, "SourceNotFound", fmt.Sprintf("Waiting on Secret %s to exist", secretName))
+	}
+	manifest, err := export.ManifestFromSecret(secret)
+	if err != nil {
+		return r.waitOnSource(crc, "InvalidSource", err.Error())
+	}
+	if crc.Spec.BundleName != "" && crc.Spec.BundleName != manifest.BundleName {
+		return r.waitOnSource(crc, "BundleMismatch", fmt.Sprintf("CrcCluster %s was exported from bundle %s, not %s", manifest.ClusterName, manifest.BundleName, crc.Spec.BundleName))
+	}
+	if crc.Spec.Storage.Size != "" && manifest.DiskSize != "" {
+		requested, err := resource.ParseQuantity(crc.Spec.Storage.Size)
+		if err != nil {
+			return crc, false, err
+		}
+		diskSize, err := resource.ParseQuantity(manifest.DiskSize)
+		if err != nil {
+			return crc, false, err
+		}
+		if requested.Cmp(diskSize) < 0 {
+			return r.waitOnSource(crc, "StorageTooSmall", fmt.Sprintf("Requested storage size %s is less than the exported disk size of %s", crc.Spec.Storage.Size, manifest.DiskSize))
+		}
+	}
+
+	// The imported disk only works with the bundle it came from
+	if crc.Spec.BundleName == "" || (crc.Spec.Storage.Size == "" && manifest.DiskSize != "") {
+		logger.Info("Updating CrcCluster bundle and storage size from its import.", "Bundle.Name", manifest.BundleName, "Storage.Size", manifest.DiskSize)
+		crc.Spec.BundleName = manifest.BundleName
+		if crc.Spec.BundleImage == "" {
+			crc.Spec.BundleImage = manifest.BundleImage
+		}
+		if crc.Spec.Storage.Size == "" {
+			crc.Spec.Storage.Size = manifest.DiskSize
+		}
+		return crc, false, r.client.Update(context.TODO(), crc)
+	}
+
+	crc.Status.SSHKey = manifest.SSHKey
+	crc.Status.ClusterID = manifest.ClusterID
+	crc.Status.KubeAdminPassword = manifest.KubeAdminPassword
+	crc.Status.KubeAdminClientKey = manifest.KubeAdminClientKey
+	setConfigStep(crc, configStepAdminUser)
+	setConfigStep(crc, configStepClusterID)
+	message := fmt.Sprintf("Imported from CrcCluster %s/%s exported at %s", manifest.Namespace, manifest.ClusterName, manifest.ExportTime.UTC())
+	crc.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeSourceNotReady, false, "Imported", message)
+	r.recorder.Event(crc, corev1.EventTypeNormal, "Imported", message)
+	crc, err = r.updateCrcClusterStatus(crc)
+	return crc, err == nil, err
+}
+
+// clonesVolume returns true if the cluster's persistent volume gets
+// cloned from another cluster or persistent volume claim
+func clonesVolume(crc *crcv1alpha1.CrcCluster) bool {
+	return createsFromSource(crc) && (crc.Spec.Source.CrcCluster != "" || crc.Spec.Source.PersistentVolumeClaim != nil)
+}
+
+// importsVolume returns true if the cluster's persistent volume gets
+// imported from an exported cluster
+func importsVolume(crc *crcv1alpha1.CrcCluster) bool {
+	return createsFromSource(crc) && crc.Spec.Source.Import != nil
+}
+
+// applyDataVolumeSource makes the cluster's DataVolume a CDI clone of
+// its source, or an import of an exported cluster, instead of an
+// import of its bundle
+func applyDataVolumeSource(vm *kubevirtv1.VirtualMachine, crc *crcv1alpha1.CrcCluster) error {
+	dataVolume := rootDataVolumeTemplate(vm)
+	if dataVolume == nil {
+		return nil
+	}
+	if importsVolume(crc) {
+		dataVolume.Spec.Source = cdiv1.DataVolumeSource{
+			HTTP: &cdiv1.DataVolumeSourceHTTP{
+				URL:           crc.Spec.Source.Import.URL,
+				SecretRef:     crc.Spec.Source.Import.SecretName,
+				CertConfigMap: crc.Spec.Source.Import.CertConfigMapName,
+			},
+		}
+		return nil
+	}
+	if !clonesVolume(crc) {
+		return nil
+	}
+	sourcePVC := &cdiv1.DataVolumeSourcePVC{
+		Namespace: crc.Namespace,
+	}
+	if crc.Spec.Source.CrcCluster != "" {
+		sourcePVC.Name = crcv1alpha1.RootVolumeName(crc.Spec.Source.CrcCluster)
+	} else {
+		sourcePVC.Name = crc.Spec.Source.PersistentVolumeClaim.Name
+		if crc.Spec.Source.PersistentVolumeClaim.Namespace != "" {
+			sourcePVC.Namespace = crc.Spec.Source.PersistentVolumeClaim.Namespace
+		}
+	}
+	if sourcePVC.Namespace != crc.Namespace && !AdmissionWebhooksEnabled {
+		return fmt.Errorf("Cloning persistent volume claims from namespace %s needs the operator's admission webhooks", sourcePVC.Namespace)
+	}
+	dataVolume.Spec.Source = cdiv1.DataVolumeSource{
+		PVC: sourcePVC,
+	}
+	return nil
+}
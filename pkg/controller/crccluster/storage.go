@@ -0,0 +1,96 @@
+package crccluster
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtv1 "kubevirt.io/client-go/api/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+)
+
+// Defaults for the persistent volumes of clusters that don't set
+// their own. DEFAULT_ACCESS_MODES is a comma-separated list.
+var defaultStorageClassName = os.Getenv("DEFAULT_STORAGE_CLASS")
+var defaultAccessModes = os.Getenv("DEFAULT_ACCESS_MODES")
+var defaultVolumeMode = os.Getenv("DEFAULT_VOLUME_MODE")
+
+// pvcSpecForCrcCluster returns the spec of the persistent volume
+// claim of a cluster with persistent storage
+func pvcSpecForCrcCluster(crc *crcv1alpha1.CrcCluster, storageQuantity resource.Quantity) (*corev1.PersistentVolumeClaimSpec, error) {
+	accessModes, err := accessModesForCrcCluster(crc)
+	if err != nil {
+		return nil, err
+	}
+	pvcSpec := &corev1.PersistentVolumeClaimSpec{
+		AccessModes: accessModes,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceStorage: storageQuantity,
+			},
+		},
+	}
+
+	storageClassName := crc.Spec.Storage.StorageClassName
+	if storageClassName == "" {
+		storageClassName = defaultStorageClassName
+	}
+	if storageClassName != "" {
+		pvcSpec.StorageClassName = &storageClassName
+	}
+
+	volumeMode := crc.Spec.Storage.VolumeMode
+	if volumeMode == "" {
+		volumeMode = defaultVolumeMode
+	}
+	switch corev1.PersistentVolumeMode(volumeMode) {
+	case "":
+	case corev1.PersistentVolumeFilesystem, corev1.PersistentVolumeBlock:
+		mode := corev1.PersistentVolumeMode(volumeMode)
+		pvcSpec.VolumeMode = &mode
+	default:
+		return nil, fmt.Errorf("Invalid volume mode %s, must be Filesystem or Block", volumeMode)
+	}
+	return pvcSpec, nil
+}
+
+// rootDataVolumeTemplate returns the template of the DataVolume the
+// bundle gets imported into, or nil if the VirtualMachine's root disk
+// isn't a DataVolume. Any other templates belong to additional disks.
+func rootDataVolumeTemplate(vm *kubevirtv1.VirtualMachine) *cdiv1.DataVolume {
+	rootVolume := vm.Spec.Template.Spec.Volumes[0]
+	if rootVolume.DataVolume == nil {
+		return nil
+	}
+	for i := range vm.Spec.DataVolumeTemplates {
+		if vm.Spec.DataVolumeTemplates[i].Name == rootVolume.DataVolume.Name {
+			return &vm.Spec.DataVolumeTemplates[i]
+		}
+	}
+	return nil
+}
+
+// accessModesForCrcCluster returns the access modes of a cluster's
+// persistent volume, defaulting to ReadWriteOnce
+func accessModesForCrcCluster(crc *crcv1alpha1.CrcCluster) ([]corev1.PersistentVolumeAccessMode, error) {
+	accessModes := crc.Spec.Storage.AccessModes
+	if len(accessModes) == 0 && defaultAccessModes != "" {
+		for _, accessMode := range strings.Split(defaultAccessModes, ",") {
+			accessModes = append(accessModes, corev1.PersistentVolumeAccessMode(strings.TrimSpace(accessMode)))
+		}
+	}
+	if len(accessModes) == 0 {
+		return []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}, nil
+	}
+	for _, accessMode := range accessModes {
+		switch accessMode {
+		case corev1.ReadWriteOnce, corev1.ReadOnlyMany, corev1.ReadWriteMany:
+		default:
+			return nil, fmt.Errorf("Invalid access mode %s, must be ReadWriteOnce, ReadOnlyMany, or ReadWriteMany", accessMode)
+		}
+	}
+	return accessModes, nil
+}
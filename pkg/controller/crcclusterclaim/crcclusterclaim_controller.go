@@ -0,0 +1,274 @@
+package crcclusterclaim
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/bbrowning/crc-operator/pkg/quota"
+	"github.com/go-logr/logr"
+	"github.com/operator-framework/operator-sdk/pkg/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_crcclusterclaim")
+
+// Add creates a new CrcClusterClaim Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileCrcClusterClaim{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("crcclusterclaim-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource CrcClusterClaim
+	err = c.Watch(&source.Kind{Type: &crcv1alpha1.CrcClusterClaim{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to secondary resource CrcClusters and
+	// requeue the owner CrcClusterClaim
+	err = c.Watch(&source.Kind{Type: &crcv1alpha1.CrcCluster{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &crcv1alpha1.CrcClusterClaim{},
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcileCrcClusterClaim implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileCrcClusterClaim{}
+
+// ReconcileCrcClusterClaim reconciles a CrcClusterClaim object
+type ReconcileCrcClusterClaim struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// Reconcile reads that state of the cluster for a CrcClusterClaim object and binds it to a Ready CrcCluster from the
+// requested CrcClusterPool. The pool controller notices the cluster left the pool and creates a replacement.
+//
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCrcClusterClaim) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling CrcClusterClaim")
+
+	// Fetch the CrcClusterClaim instance
+	existingClaim := &crcv1alpha1.CrcClusterClaim{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, existingClaim)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			reqLogger.Info("CrcClusterClaim resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		reqLogger.Error(err, "Failed to get CrcClusterClaim.")
+		return reconcile.Result{}, err
+	}
+	claim := existingClaim.DeepCopy()
+
+	// Initialize status conditions
+	if len(claim.Status.Conditions) == 0 {
+		claim.Status.Conditions = status.NewConditions(
+			status.Condition{
+				Type:   crcv1alpha1.ConditionTypeClusterNotBound,
+				Status: corev1.ConditionTrue,
+			},
+			status.Condition{
+				Type:   crcv1alpha1.ConditionTypeQuotaExceeded,
+				Status: corev1.ConditionFalse,
+			},
+			status.Condition{
+				Type:   crcv1alpha1.ConditionTypeReady,
+				Status: corev1.ConditionFalse,
+			},
+		)
+	}
+
+	crc, err := r.boundCrcCluster(claim)
+	if err != nil {
+		reqLogger.Error(err, "Failed to get bound CrcCluster.")
+		return reconcile.Result{}, err
+	}
+
+	if crc == nil {
+		if claim.Status.ClusterName != "" {
+			// Our cluster got deleted out from under us
+			claim.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeClusterNotBound, true, "ClusterDeleted", fmt.Sprintf("The bound CrcCluster %s was deleted", claim.Status.ClusterName))
+			claim.SetConditionBool(crcv1alpha1.ConditionTypeReady, false)
+			return reconcile.Result{}, r.updateClaimStatus(reqLogger, claim, existingClaim)
+		}
+
+		crc, err = r.bindCrcCluster(reqLogger, claim)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if crc == nil {
+			if err := r.updateClaimStatus(reqLogger, claim, existingClaim); err != nil {
+				return reconcile.Result{}, err
+			}
+			return reconcile.Result{RequeueAfter: time.Second * 10}, nil
+		}
+	}
+	claim.Status.ClusterName = crc.Name
+	claim.SetConditionBool(crcv1alpha1.ConditionTypeClusterNotBound, false)
+	claim.SetConditionBool(crcv1alpha1.ConditionTypeQuotaExceeded, false)
+
+	// Only hand out credentials once they've been flagged for
+	// rotation for this claim and rotated
+	crcReady := crc.Status.Claim == claim.Name &&
+		crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeReady) &&
+		!crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeCredentialsNotRotated)
+	if crcReady {
+		claim.Status.APIURL = crc.Status.APIURL
+		claim.Status.ConsoleURL = crc.Status.ConsoleURL
+		claim.Status.Kubeconfig = crc.Status.Kubeconfig
+		claim.Status.KubeAdminPassword = crc.Status.KubeAdminPassword
+	}
+	claim.SetConditionBool(crcv1alpha1.ConditionTypeReady, crcReady)
+
+	return reconcile.Result{}, r.updateClaimStatus(reqLogger, claim, existingClaim)
+}
+
+// boundCrcCluster returns the CrcCluster already bound to this claim,
+// or nil if there isn't one
+func (r *ReconcileCrcClusterClaim) boundCrcCluster(claim *crcv1alpha1.CrcClusterClaim) (*crcv1alpha1.CrcCluster, error) {
+	if claim.Status.ClusterName != "" {
+		crc := &crcv1alpha1.CrcCluster{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: claim.Status.ClusterName, Namespace: claim.Namespace}, crc)
+		if err != nil && errors.IsNotFound(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if !metav1.IsControlledBy(crc, claim) {
+			// Our cache hasn't caught up with the binding yet
+			return nil, fmt.Errorf("CrcCluster %s is not yet bound to this claim", crc.Name)
+		}
+		return crc, nil
+	}
+
+	// We may have bound a cluster without getting to record it in
+	// our status, so look for it by label
+	crcList := &crcv1alpha1.CrcClusterList{}
+	err := r.client.List(context.TODO(), crcList, client.InNamespace(claim.Namespace), client.MatchingLabels{crcv1alpha1.ClaimLabel: claim.Name})
+	if err != nil {
+		return nil, err
+	}
+	for _, crc := range crcList.Items {
+		if metav1.IsControlledBy(&crc, claim) {
+			copiedCrc := crc
+			return &copiedCrc, nil
+		}
+	}
+	return nil, nil
+}
+
+// bindCrcCluster takes the oldest Ready CrcCluster out of the pool and
+// hands it to the claimer. It returns nil if no cluster could be
+// bound yet.
+func (r *ReconcileCrcClusterClaim) bindCrcCluster(logger logr.Logger, claim *crcv1alpha1.CrcClusterClaim) (*crcv1alpha1.CrcCluster, error) {
+	crcList := &crcv1alpha1.CrcClusterList{}
+	err := r.client.List(context.TODO(), crcList, client.InNamespace(claim.Namespace), client.MatchingLabels{crcv1alpha1.PoolLabel: claim.Spec.PoolName})
+	if err != nil {
+		logger.Error(err, "Failed to list pooled CrcClusters.")
+		return nil, err
+	}
+	readyCrcs := []crcv1alpha1.CrcCluster{}
+	for _, crc := range crcList.Items {
+		if crc.DeletionTimestamp == nil && crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeReady) {
+			readyCrcs = append(readyCrcs, crc)
+		}
+	}
+	if len(readyCrcs) == 0 {
+		logger.Info("Waiting on a Ready CrcCluster in the pool.", "Pool", claim.Spec.PoolName)
+		claim.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeClusterNotBound, true, "NoReadyClusters", fmt.Sprintf("Waiting on a Ready cluster in CrcClusterPool %s", claim.Spec.PoolName))
+		return nil, nil
+	}
+	sort.SliceStable(readyCrcs, func(i, j int) bool {
+		return readyCrcs[i].CreationTimestamp.Before(&readyCrcs[j].CreationTimestamp)
+	})
+	crc := readyCrcs[0].DeepCopy()
+
+	// Make sure the claimer has enough quota for this cluster
+	if crc.Annotations == nil {
+		crc.Annotations = map[string]string{}
+	}
+	crc.Annotations[crcv1alpha1.RequesterAnnotation] = quota.Requester(claim)
+	if claim.Spec.PullSecret != "" {
+		crc.Spec.PullSecret = claim.Spec.PullSecret
+	}
+	if err := quota.Check(r.client, crc); err != nil {
+		if !quota.IsExceeded(err) {
+			logger.Error(err, "Failed to check CrcClusterQuotas.")
+			return nil, err
+		}
+		logger.Info("Waiting on CrcClusterQuota to allow this claim.", "Reason", err.Error())
+		claim.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeQuotaExceeded, true, "QuotaExceeded", err.Error())
+		return nil, nil
+	}
+
+	// Binding is a single write of the claim label and owner, which
+	// boundCrcCluster finds the cluster by if anything after this
+	// fails. The CrcCluster controller flags the credentials for
+	// rotation as soon as the claim label shows up.
+	logger.Info("Binding pooled CrcCluster.", "CrcCluster.Name", crc.Name)
+	delete(crc.Labels, crcv1alpha1.PoolLabel)
+	crc.Labels[crcv1alpha1.ClaimLabel] = claim.Name
+	crc.OwnerReferences = nil
+	if err := controllerutil.SetControllerReference(claim, crc, r.scheme); err != nil {
+		return nil, err
+	}
+	if err := r.client.Update(context.TODO(), crc); err != nil {
+		logger.Error(err, "Failed to bind pooled CrcCluster.", "CrcCluster.Name", crc.Name)
+		return nil, err
+	}
+	return crc, nil
+}
+
+func (r *ReconcileCrcClusterClaim) updateClaimStatus(logger logr.Logger, claim *crcv1alpha1.CrcClusterClaim, existingClaim *crcv1alpha1.CrcClusterClaim) error {
+	if !reflect.DeepEqual(claim.Status, existingClaim.Status) {
+		if err := r.client.Status().Update(context.TODO(), claim); err != nil {
+			logger.Error(err, "Failed to update CrcClusterClaim status.")
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,621 @@
+package crcclusterexport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/bbrowning/crc-operator/pkg/bundle"
+	crcexport "github.com/bbrowning/crc-operator/pkg/export"
+	"github.com/go-logr/logr"
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/operator-framework/operator-sdk/pkg/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_crcclusterexport")
+
+// operatorImage is the image of the operator, whose binary also
+// serves the disks of exported clusters
+var operatorImage = os.Getenv("OPERATOR_IMAGE")
+
+// Add creates a new CrcClusterExport Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	if operatorImage == "" {
+		log.Error(fmt.Errorf("OPERATOR_IMAGE environment variable must be set"), "")
+		os.Exit(1)
+	}
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileCrcClusterExport{
+		client:         mgr.GetClient(),
+		scheme:         mgr.GetScheme(),
+		routeAPIExists: routeAPIExists(mgr),
+	}
+}
+
+func routeAPIExists(mgr manager.Manager) bool {
+	gvk := schema.GroupVersionKind{
+		Group:   "route.openshift.io",
+		Kind:    "Route",
+		Version: "v1",
+	}
+	_, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	return err == nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("crcclusterexport-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource CrcClusterExport
+	err = c.Watch(&source.Kind{Type: &crcv1alpha1.CrcClusterExport{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to the CrcCluster being exported, which
+	// carries the name of the export in an annotation while it's
+	// kept stopped for it
+	err = c.Watch(&source.Kind{Type: &crcv1alpha1.CrcCluster{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(o handler.MapObject) []reconcile.Request {
+			exportName := o.Meta.GetAnnotations()[crcv1alpha1.ExportAnnotation]
+			if exportName == "" {
+				return nil
+			}
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: exportName, Namespace: o.Meta.GetNamespace()}},
+			}
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to the Pod serving the export
+	err = c.Watch(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &crcv1alpha1.CrcClusterExport{},
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcileCrcClusterExport implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileCrcClusterExport{}
+
+// ReconcileCrcClusterExport reconciles a CrcClusterExport object
+type ReconcileCrcClusterExport struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client         client.Client
+	scheme         *runtime.Scheme
+	routeAPIExists bool
+}
+
+// Reconcile reads that state of the cluster for a CrcClusterExport object and exports its CrcCluster. The
+// CrcCluster gets stopped gracefully through an annotation, and a Pod serves its disk image and a manifest of its
+// credentials and metadata until the export gets deleted.
+//
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCrcClusterExport) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling CrcClusterExport")
+
+	// Fetch the CrcClusterExport instance
+	existingExport := &crcv1alpha1.CrcClusterExport{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, existingExport)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			reqLogger.Info("CrcClusterExport resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		reqLogger.Error(err, "Failed to get CrcClusterExport.")
+		return reconcile.Result{}, err
+	}
+	export := existingExport.DeepCopy()
+
+	// Initialize status conditions
+	if len(export.Status.Conditions) == 0 {
+		export.Status.Phase = crcv1alpha1.CrcClusterExportPhasePending
+		export.Status.Conditions = status.NewConditions(
+			status.Condition{
+				Type:   crcv1alpha1.ConditionTypeReady,
+				Status: corev1.ConditionFalse,
+			},
+		)
+	}
+
+	if export.Status.Phase == crcv1alpha1.CrcClusterExportPhaseFailed {
+		return reconcile.Result{}, nil
+	}
+
+	crc := &crcv1alpha1.CrcCluster{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: export.Spec.ClusterName, Namespace: export.Namespace}, crc)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			r.fail(export, "ClusterNotFound", fmt.Sprintf("CrcCluster %s was not found", export.Spec.ClusterName))
+			return reconcile.Result{}, r.updateExportStatus(reqLogger, export, existingExport)
+		}
+		reqLogger.Error(err, "Failed to get CrcCluster.")
+		return reconcile.Result{}, err
+	}
+	if !crc.Spec.Storage.Persistent {
+		r.fail(export, "NotPersistent", fmt.Sprintf("CrcCluster %s doesn't have persistent storage", crc.Name))
+		return reconcile.Result{}, r.updateExportStatus(reqLogger, export, existingExport)
+	}
+
+	var result reconcile.Result
+	switch export.Status.Phase {
+	case crcv1alpha1.CrcClusterExportPhasePending:
+		result, err = r.quiesce(reqLogger, export, crc)
+	case crcv1alpha1.CrcClusterExportPhaseQuiescing:
+		result, err = r.writeManifest(reqLogger, export, crc)
+	case crcv1alpha1.CrcClusterExportPhaseExporting, crcv1alpha1.CrcClusterExportPhaseReady:
+		result, err = r.serveExport(reqLogger, export, crc)
+	}
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	return result, r.updateExportStatus(reqLogger, export, existingExport)
+}
+
+// quiesce annotates the cluster so the CrcCluster controller shuts it
+// down gracefully and keeps it stopped
+func (r *ReconcileCrcClusterExport) quiesce(logger logr.Logger, export *crcv1alpha1.CrcClusterExport, crc *crcv1alpha1.CrcCluster) (reconcile.Result, error) {
+	if other := crc.Annotations[crcv1alpha1.ExportAnnotation]; other != "" && other != export.Name {
+		logger.Info("Waiting on another export of the CrcCluster.", "Export", other)
+		export.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeReady, false, "ExportInProgress", fmt.Sprintf("Waiting on CrcClusterExport %s to be deleted", other))
+		return reconcile.Result{RequeueAfter: time.Second * 30}, nil
+	}
+	if crc.Status.SSHKey == "" {
+		logger.Info("Waiting on the CrcCluster to be provisioned before exporting it.")
+		export.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeReady, false, "ClusterNotProvisioned", fmt.Sprintf("Waiting on CrcCluster %s to be provisioned", crc.Name))
+		return reconcile.Result{RequeueAfter: time.Second * 30}, nil
+	}
+
+	if crc.Annotations[crcv1alpha1.ExportAnnotation] != export.Name {
+		logger.Info("Stopping CrcCluster to export it.", "CrcCluster.Name", crc.Name)
+		if crc.Annotations == nil {
+			crc.Annotations = map[string]string{}
+		}
+		crc.Annotations[crcv1alpha1.ExportAnnotation] = export.Name
+		if err := r.client.Update(context.TODO(), crc); err != nil {
+			logger.Error(err, "Failed to annotate CrcCluster.")
+			return reconcile.Result{}, err
+		}
+	}
+	export.Status.Phase = crcv1alpha1.CrcClusterExportPhaseQuiescing
+	export.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeReady, false, "Quiescing", fmt.Sprintf("Shutting down CrcCluster %s gracefully", crc.Name))
+	return reconcile.Result{RequeueAfter: time.Second * 10}, nil
+}
+
+// writeManifest records everything needed to import the cluster, and
+// a username and password to download it with, in the export's
+// Secret once the cluster is stopped
+func (r *ReconcileCrcClusterExport) writeManifest(logger logr.Logger, export *crcv1alpha1.CrcClusterExport, crc *crcv1alpha1.CrcCluster) (reconcile.Result, error) {
+	if crc.Status.PowerState != crcv1alpha1.CrcClusterPowerStateStopped {
+		logger.Info("Waiting on the CrcCluster to stop.", "PowerState", crc.Status.PowerState)
+		return reconcile.Result{RequeueAfter: time.Second * 10}, nil
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: crcv1alpha1.RootVolumeName(crc.Name), Namespace: crc.Namespace}, pvc)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			r.fail(export, "VolumeNotFound", fmt.Sprintf("PersistentVolumeClaim %s of CrcCluster %s was not found", crcv1alpha1.RootVolumeName(crc.Name), crc.Name))
+			return reconcile.Result{}, r.release(logger, export, crc)
+		}
+		return reconcile.Result{}, err
+	}
+	diskSize := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		diskSize = capacity
+	}
+
+	crcBundle, _, err := bundle.ForCrcCluster(r.client, crc)
+	if err != nil {
+		logger.Error(err, "Failed to get bundle for CrcCluster.")
+		return reconcile.Result{}, err
+	}
+	spec := crc.Spec.DeepCopy()
+	spec.Source = nil
+	spec.ResetRequestedAt = nil
+	manifest := &crcexport.Manifest{
+		ClusterName:        crc.Name,
+		Namespace:          crc.Namespace,
+		ExportTime:         metav1.Now().Rfc3339Copy(),
+		BundleName:         crcBundle.Name,
+		BundleImage:        crc.Spec.BundleImage,
+		DiskSize:           diskSize.String(),
+		Spec:               *spec,
+		ClusterID:          crc.Status.ClusterID,
+		KubeAdminPassword:  crc.Status.KubeAdminPassword,
+		KubeAdminClientKey: crc.Status.KubeAdminClientKey,
+		SSHKey:             crc.Status.SSHKey,
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	password, err := generatePassword()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      exportResourceName(export),
+			Namespace: export.Namespace,
+			Labels:    exportLabels(export),
+		},
+		Data: map[string][]byte{
+			crcexport.ManifestKey: manifestJSON,
+			crcexport.UsernameKey: []byte("crc-export"),
+			crcexport.PasswordKey: []byte(password),
+		},
+	}
+	if err := controllerutil.SetControllerReference(export, secret, r.scheme); err != nil {
+		return reconcile.Result{}, err
+	}
+	logger.Info("Creating export Secret.", "Secret.Name", secret.Name)
+	if err := r.client.Create(context.TODO(), secret); err != nil && !errors.IsAlreadyExists(err) {
+		logger.Error(err, "Failed to create export Secret.")
+		return reconcile.Result{}, err
+	}
+	export.Status.SecretName = secret.Name
+	export.Status.Phase = crcv1alpha1.CrcClusterExportPhaseExporting
+	export.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeReady, false, "Exporting", "Starting the export server")
+	return reconcile.Result{RequeueAfter: time.Second * 5}, nil
+}
+
+// serveExport makes sure the Pod, Service, and Route serving the
+// export exist and marks the export Ready with its URLs once the Pod
+// is ready
+func (r *ReconcileCrcClusterExport) serveExport(logger logr.Logger, export *crcv1alpha1.CrcClusterExport, crc *crcv1alpha1.CrcCluster) (reconcile.Result, error) {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: crcv1alpha1.RootVolumeName(crc.Name), Namespace: crc.Namespace}, pvc); err != nil {
+		return reconcile.Result{}, err
+	}
+	pod, err := r.ensurePodExists(logger, export, pvc)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.ensureServiceExists(logger, export); err != nil {
+		return reconcile.Result{}, err
+	}
+	baseURL := fmt.Sprintf("http://%s.%s.svc:%d", exportResourceName(export), export.Namespace, crcexport.ServerPort)
+	if r.routeAPIExists {
+		route, err := r.ensureRouteExists(logger, export)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if route.Spec.Host == "" {
+			logger.Info("Waiting on export Route to get a host.")
+			return reconcile.Result{RequeueAfter: time.Second * 5}, nil
+		}
+		baseURL = fmt.Sprintf("https://%s", route.Spec.Host)
+	}
+
+	if !podReady(pod) {
+		logger.Info("Waiting on export Pod to be ready.", "Pod.Name", pod.Name)
+		export.Status.Phase = crcv1alpha1.CrcClusterExportPhaseExporting
+		export.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeReady, false, "Exporting", fmt.Sprintf("Waiting on Pod %s to be ready", pod.Name))
+		return reconcile.Result{RequeueAfter: time.Second * 10}, nil
+	}
+
+	export.Status.DiskURL = baseURL + crcexport.DiskPath
+	export.Status.ManifestURL = baseURL + crcexport.ManifestPath
+	if export.Status.ExportTime == nil {
+		exportTime := metav1.Now()
+		export.Status.ExportTime = &exportTime
+	}
+	export.Status.Phase = crcv1alpha1.CrcClusterExportPhaseReady
+	export.SetConditionBool(crcv1alpha1.ConditionTypeReady, true)
+	return reconcile.Result{}, nil
+}
+
+func (r *ReconcileCrcClusterExport) ensurePodExists(logger logr.Logger, export *crcv1alpha1.CrcClusterExport, pvc *corev1.PersistentVolumeClaim) (*corev1.Pod, error) {
+	pod, err := r.newPodForExport(export, pvc)
+	if err != nil {
+		return nil, err
+	}
+	existingPod := &corev1.Pod{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, existingPod)
+	if err == nil {
+		return existingPod, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+	logger.Info("Creating export Pod.", "Pod.Name", pod.Name)
+	if err := r.client.Create(context.TODO(), pod); err != nil && !errors.IsAlreadyExists(err) {
+		logger.Error(err, "Failed to create export Pod.")
+		return nil, err
+	}
+	return pod, nil
+}
+
+func (r *ReconcileCrcClusterExport) newPodForExport(export *crcv1alpha1.CrcClusterExport, pvc *corev1.PersistentVolumeClaim) (*corev1.Pod, error) {
+	secretName := exportResourceName(export)
+	container := corev1.Container{
+		Name:            "export-server",
+		Image:           operatorImage,
+		ImagePullPolicy: corev1.PullAlways,
+		Command:         []string{"crc-operator", crcexport.ServerCommand},
+		Env: []corev1.EnvVar{
+			{
+				Name:  crcexport.ManifestPathEnv,
+				Value: "/etc/crc-export/" + crcexport.ManifestKey,
+			},
+			{
+				Name: crcexport.UsernameEnv,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+						Key:                  crcexport.UsernameKey,
+					},
+				},
+			},
+			{
+				Name: crcexport.PasswordEnv,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+						Key:                  crcexport.PasswordKey,
+					},
+				},
+			},
+		},
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          "http",
+				ContainerPort: crcexport.ServerPort,
+			},
+		},
+		ReadinessProbe: &corev1.Probe{
+			Handler: corev1.Handler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: crcexport.HealthPath,
+					Port: intstr.FromInt(crcexport.ServerPort),
+				},
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "manifest",
+				MountPath: "/etc/crc-export",
+				ReadOnly:  true,
+			},
+		},
+	}
+	// CDI puts the disk image in a disk.img file on Filesystem
+	// volumes and directly on the device of Block volumes
+	if pvc.Spec.VolumeMode != nil && *pvc.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+		container.VolumeDevices = []corev1.VolumeDevice{
+			{
+				Name:       "disk",
+				DevicePath: "/dev/crc-disk",
+			},
+		}
+		container.Env = append(container.Env, corev1.EnvVar{Name: crcexport.DiskPathEnv, Value: "/dev/crc-disk"})
+	} else {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      "disk",
+			MountPath: "/disk",
+			ReadOnly:  true,
+		})
+		container.Env = append(container.Env, corev1.EnvVar{Name: crcexport.DiskPathEnv, Value: "/disk/disk.img"})
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      exportResourceName(export),
+			Namespace: export.Namespace,
+			Labels:    exportLabels(export),
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{container},
+			Volumes: []corev1.Volume{
+				{
+					Name: "disk",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: pvc.Name,
+							ReadOnly:  true,
+						},
+					},
+				},
+				{
+					Name: "manifest",
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{
+							SecretName: secretName,
+							Items: []corev1.KeyToPath{
+								{
+									Key:  crcexport.ManifestKey,
+									Path: crcexport.ManifestKey,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := controllerutil.SetControllerReference(export, pod, r.scheme); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+func (r *ReconcileCrcClusterExport) ensureServiceExists(logger logr.Logger, export *crcv1alpha1.CrcClusterExport) error {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      exportResourceName(export),
+			Namespace: export.Namespace,
+			Labels:    exportLabels(export),
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: exportLabels(export),
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Port:       crcexport.ServerPort,
+					TargetPort: intstr.FromInt(crcexport.ServerPort),
+				},
+			},
+		},
+	}
+	if err := controllerutil.SetControllerReference(export, service, r.scheme); err != nil {
+		return err
+	}
+	existingService := &corev1.Service{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: service.Name, Namespace: service.Namespace}, existingService)
+	if err == nil || !errors.IsNotFound(err) {
+		return err
+	}
+	logger.Info("Creating export Service.", "Service.Name", service.Name)
+	if err := r.client.Create(context.TODO(), service); err != nil && !errors.IsAlreadyExists(err) {
+		logger.Error(err, "Failed to create export Service.")
+		return err
+	}
+	return nil
+}
+
+func (r *ReconcileCrcClusterExport) ensureRouteExists(logger logr.Logger, export *crcv1alpha1.CrcClusterExport) (*routev1.Route, error) {
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      exportResourceName(export),
+			Namespace: export.Namespace,
+			Labels:    exportLabels(export),
+		},
+		Spec: routev1.RouteSpec{
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromInt(crcexport.ServerPort),
+			},
+			To: routev1.RouteTargetReference{
+				Kind: "Service",
+				Name: exportResourceName(export),
+			},
+			TLS: &routev1.TLSConfig{
+				Termination:                   routev1.TLSTerminationEdge,
+				InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+			},
+		},
+	}
+	if err := controllerutil.SetControllerReference(export, route, r.scheme); err != nil {
+		return nil, err
+	}
+	existingRoute := &routev1.Route{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, existingRoute)
+	if err == nil {
+		return existingRoute, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+	logger.Info("Creating export Route.", "Route.Name", route.Name)
+	if err := r.client.Create(context.TODO(), route); err != nil && !errors.IsAlreadyExists(err) {
+		logger.Error(err, "Failed to create export Route.")
+		return nil, err
+	}
+	return route, nil
+}
+
+// fail marks the export as Failed for the given reason
+func (r *ReconcileCrcClusterExport) fail(export *crcv1alpha1.CrcClusterExport, reason status.ConditionReason, message string) {
+	export.Status.Phase = crcv1alpha1.CrcClusterExportPhaseFailed
+	export.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeReady, false, reason, message)
+}
+
+// release removes the export's annotation from the CrcCluster so it
+// gets started again
+func (r *ReconcileCrcClusterExport) release(logger logr.Logger, export *crcv1alpha1.CrcClusterExport, crc *crcv1alpha1.CrcCluster) error {
+	if crc.Annotations[crcv1alpha1.ExportAnnotation] != export.Name {
+		return nil
+	}
+	logger.Info("Starting CrcCluster again after failing to export it.", "CrcCluster.Name", crc.Name)
+	delete(crc.Annotations, crcv1alpha1.ExportAnnotation)
+	if err := r.client.Update(context.TODO(), crc); err != nil {
+		logger.Error(err, "Failed to remove export annotation from CrcCluster.")
+		return err
+	}
+	return nil
+}
+
+func (r *ReconcileCrcClusterExport) updateExportStatus(logger logr.Logger, export *crcv1alpha1.CrcClusterExport, existingExport *crcv1alpha1.CrcClusterExport) error {
+	if !reflect.DeepEqual(export.Status, existingExport.Status) {
+		if err := r.client.Status().Update(context.TODO(), export); err != nil {
+			logger.Error(err, "Failed to update CrcClusterExport status.")
+			return err
+		}
+	}
+	return nil
+}
+
+// exportResourceName is the name of the Secret, Pod, Service, and
+// Route of an export
+func exportResourceName(export *crcv1alpha1.CrcClusterExport) string {
+	return fmt.Sprintf("%s-export", export.Name)
+}
+
+func exportLabels(export *crcv1alpha1.CrcClusterExport) map[string]string {
+	return map[string]string{
+		"crcClusterExport": export.Name,
+	}
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func generatePassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,187 @@
+package crcclusterpool
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_crcclusterpool")
+
+// Add creates a new CrcClusterPool Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileCrcClusterPool{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("crcclusterpool-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource CrcClusterPool
+	err = c.Watch(&source.Kind{Type: &crcv1alpha1.CrcClusterPool{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to secondary resource CrcClusters and
+	// requeue the owner CrcClusterPool. This includes clusters
+	// getting claimed, since the old owner gets requeued as well.
+	err = c.Watch(&source.Kind{Type: &crcv1alpha1.CrcCluster{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &crcv1alpha1.CrcClusterPool{},
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcileCrcClusterPool implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileCrcClusterPool{}
+
+// ReconcileCrcClusterPool reconciles a CrcClusterPool object
+type ReconcileCrcClusterPool struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// Reconcile reads that state of the cluster for a CrcClusterPool object and creates or deletes unclaimed CrcClusters
+// to keep the pool at its desired size.
+//
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCrcClusterPool) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling CrcClusterPool")
+
+	// Fetch the CrcClusterPool instance
+	existingPool := &crcv1alpha1.CrcClusterPool{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, existingPool)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			reqLogger.Info("CrcClusterPool resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		reqLogger.Error(err, "Failed to get CrcClusterPool.")
+		return reconcile.Result{}, err
+	}
+	pool := existingPool.DeepCopy()
+
+	crcList := &crcv1alpha1.CrcClusterList{}
+	err = r.client.List(context.TODO(), crcList, client.InNamespace(pool.Namespace), client.MatchingLabels{crcv1alpha1.PoolLabel: pool.Name})
+	if err != nil {
+		reqLogger.Error(err, "Failed to list pooled CrcClusters.")
+		return reconcile.Result{}, err
+	}
+	pooledCrcs := []crcv1alpha1.CrcCluster{}
+	for _, crc := range crcList.Items {
+		if crc.DeletionTimestamp == nil && crc.Labels[crcv1alpha1.ClaimLabel] == "" {
+			pooledCrcs = append(pooledCrcs, crc)
+		}
+	}
+
+	// Scale down by deleting the clusters furthest from being
+	// claimable first - not Ready, then newest
+	if len(pooledCrcs) > pool.Spec.Size {
+		sort.SliceStable(pooledCrcs, func(i, j int) bool {
+			iReady := pooledCrcs[i].Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeReady)
+			jReady := pooledCrcs[j].Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeReady)
+			if iReady != jReady {
+				return !iReady
+			}
+			return pooledCrcs[j].CreationTimestamp.Before(&pooledCrcs[i].CreationTimestamp)
+		})
+		for _, crc := range pooledCrcs[:len(pooledCrcs)-pool.Spec.Size] {
+			reqLogger.Info("Deleting pooled CrcCluster.", "CrcCluster.Name", crc.Name)
+			copiedCrc := crc
+			if err := r.client.Delete(context.TODO(), &copiedCrc); err != nil && !errors.IsNotFound(err) {
+				reqLogger.Error(err, "Failed to delete pooled CrcCluster.", "CrcCluster.Name", crc.Name)
+				return reconcile.Result{}, err
+			}
+		}
+		pooledCrcs = pooledCrcs[len(pooledCrcs)-pool.Spec.Size:]
+	}
+
+	// Refill the pool
+	for i := len(pooledCrcs); i < pool.Spec.Size; i++ {
+		crc, err := r.newCrcClusterForPool(pool)
+		if err != nil {
+			reqLogger.Error(err, "Failed to create pooled CrcCluster.")
+			return reconcile.Result{}, err
+		}
+		reqLogger.Info("Creating a new pooled CrcCluster.")
+		if err := r.client.Create(context.TODO(), crc); err != nil {
+			reqLogger.Error(err, "Failed to create pooled CrcCluster.")
+			return reconcile.Result{}, err
+		}
+		pooledCrcs = append(pooledCrcs, *crc)
+	}
+
+	pool.Status.Clusters = len(pooledCrcs)
+	pool.Status.Ready = 0
+	for _, crc := range pooledCrcs {
+		if crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeReady) {
+			pool.Status.Ready++
+		}
+	}
+	if !reflect.DeepEqual(pool.Status, existingPool.Status) {
+		if err := r.client.Status().Update(context.TODO(), pool); err != nil {
+			reqLogger.Error(err, "Failed to update CrcClusterPool status.")
+			return reconcile.Result{}, err
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+func (r *ReconcileCrcClusterPool) newCrcClusterForPool(pool *crcv1alpha1.CrcClusterPool) (*crcv1alpha1.CrcCluster, error) {
+	crc := &crcv1alpha1.CrcCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-", pool.Name),
+			Namespace:    pool.Namespace,
+			Labels: map[string]string{
+				crcv1alpha1.PoolLabel: pool.Name,
+			},
+		},
+		Spec: *pool.Spec.Template.DeepCopy(),
+	}
+	// Pooled clusters have to be running to be Ready when claimed
+	crc.Spec.Stopped = false
+
+	if err := controllerutil.SetControllerReference(pool, crc, r.scheme); err != nil {
+		return crc, err
+	}
+	return crc, nil
+}
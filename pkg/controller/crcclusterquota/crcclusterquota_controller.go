@@ -0,0 +1,139 @@
+package crcclusterquota
+
+import (
+	"context"
+	"reflect"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/bbrowning/crc-operator/pkg/quota"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_crcclusterquota")
+
+// Add creates a new CrcClusterQuota Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileCrcClusterQuota{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("crcclusterquota-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource CrcClusterQuota
+	err = c.Watch(&source.Kind{Type: &crcv1alpha1.CrcClusterQuota{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to CrcClusters and requeue all
+	// CrcClusterQuotas in the same namespace so their usage stays
+	// up to date
+	err = c.Watch(&source.Kind{Type: &crcv1alpha1.CrcCluster{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			quotaList := &crcv1alpha1.CrcClusterQuotaList{}
+			err := mgr.GetClient().List(context.TODO(), quotaList, &client.ListOptions{Namespace: obj.Meta.GetNamespace()})
+			if err != nil {
+				log.Error(err, "Failed to list CrcClusterQuotas.")
+				return nil
+			}
+			requests := []reconcile.Request{}
+			for _, quota := range quotaList.Items {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: quota.Name, Namespace: quota.Namespace},
+				})
+			}
+			return requests
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcileCrcClusterQuota implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileCrcClusterQuota{}
+
+// ReconcileCrcClusterQuota reconciles a CrcClusterQuota object
+type ReconcileCrcClusterQuota struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// Reconcile reads that state of the cluster for a CrcClusterQuota object and updates its status with the resources
+// currently used by CrcClusters in its namespace. Enforcing the quota happens in the CrcCluster admission webhook and
+// controller.
+//
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCrcClusterQuota) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling CrcClusterQuota")
+
+	// Fetch the CrcClusterQuota instance
+	existingQuota := &crcv1alpha1.CrcClusterQuota{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, existingQuota)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			reqLogger.Info("CrcClusterQuota resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		reqLogger.Error(err, "Failed to get CrcClusterQuota.")
+		return reconcile.Result{}, err
+	}
+	crcQuota := existingQuota.DeepCopy()
+
+	total, users, err := quota.NamespaceUsage(r.client, crcQuota.Namespace, "")
+	if err != nil {
+		reqLogger.Error(err, "Failed to calculate CrcCluster usage.")
+		return reconcile.Result{}, err
+	}
+
+	crcQuota.Status.Used = total.ToStatus()
+	crcQuota.Status.Users = nil
+	if crcQuota.Spec.Scope == crcv1alpha1.CrcClusterQuotaScopeUser {
+		for _, user := range quota.UserNames(users) {
+			crcQuota.Status.Users = append(crcQuota.Status.Users, crcv1alpha1.CrcClusterQuotaUserUsage{
+				User:                 user,
+				CrcClusterQuotaUsage: users[user].ToStatus(),
+			})
+		}
+	}
+
+	if !reflect.DeepEqual(crcQuota.Status, existingQuota.Status) {
+		if err := r.client.Status().Update(context.TODO(), crcQuota); err != nil {
+			reqLogger.Error(err, "Failed to update CrcClusterQuota status.")
+			return reconcile.Result{}, err
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
@@ -0,0 +1,330 @@
+package crcclustersnapshot
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/bbrowning/crc-operator/pkg/bundle"
+	"github.com/go-logr/logr"
+	"github.com/operator-framework/operator-sdk/pkg/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_crcclustersnapshot")
+
+// volumeSnapshotGVK is the kind of the CSI snapshots of a cluster's
+// persistent volume. Its Go types aren't vendored, so
+// VolumeSnapshots are handled as unstructured objects.
+var volumeSnapshotGVK = schema.GroupVersionKind{
+	Group:   "snapshot.storage.k8s.io",
+	Version: "v1beta1",
+	Kind:    "VolumeSnapshot",
+}
+
+// Add creates a new CrcClusterSnapshot Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileCrcClusterSnapshot{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("crcclustersnapshot-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource CrcClusterSnapshot
+	err = c.Watch(&source.Kind{Type: &crcv1alpha1.CrcClusterSnapshot{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to the CrcCluster being snapshotted, which
+	// carries the name of the snapshot in an annotation while it's
+	// stopped for it
+	err = c.Watch(&source.Kind{Type: &crcv1alpha1.CrcCluster{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(o handler.MapObject) []reconcile.Request {
+			snapshotName := o.Meta.GetAnnotations()[crcv1alpha1.SnapshotAnnotation]
+			if snapshotName == "" {
+				return nil
+			}
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: snapshotName, Namespace: o.Meta.GetNamespace()}},
+			}
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcileCrcClusterSnapshot implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileCrcClusterSnapshot{}
+
+// ReconcileCrcClusterSnapshot reconciles a CrcClusterSnapshot object
+type ReconcileCrcClusterSnapshot struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// Reconcile reads that state of the cluster for a CrcClusterSnapshot object and snapshots its CrcCluster. The
+// CrcCluster gets stopped gracefully through an annotation, its persistent volume gets snapshotted, and then it gets
+// started again.
+//
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCrcClusterSnapshot) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling CrcClusterSnapshot")
+
+	// Fetch the CrcClusterSnapshot instance
+	existingSnapshot := &crcv1alpha1.CrcClusterSnapshot{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, existingSnapshot)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			reqLogger.Info("CrcClusterSnapshot resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		reqLogger.Error(err, "Failed to get CrcClusterSnapshot.")
+		return reconcile.Result{}, err
+	}
+	snapshot := existingSnapshot.DeepCopy()
+
+	// Initialize status conditions
+	if len(snapshot.Status.Conditions) == 0 {
+		snapshot.Status.Phase = crcv1alpha1.CrcClusterSnapshotPhasePending
+		snapshot.Status.Conditions = status.NewConditions(
+			status.Condition{
+				Type:   crcv1alpha1.ConditionTypeReady,
+				Status: corev1.ConditionFalse,
+			},
+		)
+	}
+
+	if snapshot.Status.Phase == crcv1alpha1.CrcClusterSnapshotPhaseReady || snapshot.Status.Phase == crcv1alpha1.CrcClusterSnapshotPhaseFailed {
+		return reconcile.Result{}, nil
+	}
+
+	crc := &crcv1alpha1.CrcCluster{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: snapshot.Spec.ClusterName, Namespace: snapshot.Namespace}, crc)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			r.fail(snapshot, "ClusterNotFound", fmt.Sprintf("CrcCluster %s was not found", snapshot.Spec.ClusterName))
+			return reconcile.Result{}, r.updateSnapshotStatus(reqLogger, snapshot, existingSnapshot)
+		}
+		reqLogger.Error(err, "Failed to get CrcCluster.")
+		return reconcile.Result{}, err
+	}
+	if !crc.Spec.Storage.Persistent {
+		r.fail(snapshot, "NotPersistent", fmt.Sprintf("CrcCluster %s doesn't have persistent storage", crc.Name))
+		return reconcile.Result{}, r.updateSnapshotStatus(reqLogger, snapshot, existingSnapshot)
+	}
+
+	var result reconcile.Result
+	switch snapshot.Status.Phase {
+	case crcv1alpha1.CrcClusterSnapshotPhasePending:
+		result, err = r.quiesce(reqLogger, snapshot, crc)
+	case crcv1alpha1.CrcClusterSnapshotPhaseQuiescing:
+		result, err = r.takeVolumeSnapshot(reqLogger, snapshot, crc)
+	case crcv1alpha1.CrcClusterSnapshotPhaseSnapshotting:
+		result, err = r.waitForVolumeSnapshot(reqLogger, snapshot, crc)
+	}
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	return result, r.updateSnapshotStatus(reqLogger, snapshot, existingSnapshot)
+}
+
+// quiesce records everything needed to restore the cluster and then
+// annotates it so the CrcCluster controller shuts it down gracefully
+func (r *ReconcileCrcClusterSnapshot) quiesce(logger logr.Logger, snapshot *crcv1alpha1.CrcClusterSnapshot, crc *crcv1alpha1.CrcCluster) (reconcile.Result, error) {
+	if other := crc.Annotations[crcv1alpha1.SnapshotAnnotation]; other != "" && other != snapshot.Name {
+		logger.Info("Waiting on another snapshot of the CrcCluster.", "Snapshot", other)
+		snapshot.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeReady, false, "SnapshotInProgress", fmt.Sprintf("Waiting on CrcClusterSnapshot %s to finish", other))
+		return reconcile.Result{RequeueAfter: time.Second * 30}, nil
+	}
+	if crc.Status.SSHKey == "" {
+		logger.Info("Waiting on the CrcCluster to be provisioned before snapshotting it.")
+		snapshot.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeReady, false, "ClusterNotProvisioned", fmt.Sprintf("Waiting on CrcCluster %s to be provisioned", crc.Name))
+		return reconcile.Result{RequeueAfter: time.Second * 30}, nil
+	}
+
+	crcBundle, _, err := bundle.ForCrcCluster(r.client, crc)
+	if err != nil {
+		logger.Error(err, "Failed to get bundle for CrcCluster.")
+		return reconcile.Result{}, err
+	}
+	snapshot.Status.BundleName = crcBundle.Name
+	snapshot.Status.BundleImage = crc.Spec.BundleImage
+	snapshot.Status.ClusterID = crc.Status.ClusterID
+	snapshot.Status.KubeAdminPassword = crc.Status.KubeAdminPassword
+	snapshot.Status.KubeAdminClientKey = crc.Status.KubeAdminClientKey
+	snapshot.Status.SSHKey = crc.Status.SSHKey
+
+	if crc.Annotations[crcv1alpha1.SnapshotAnnotation] != snapshot.Name {
+		logger.Info("Stopping CrcCluster to snapshot it.", "CrcCluster.Name", crc.Name)
+		if crc.Annotations == nil {
+			crc.Annotations = map[string]string{}
+		}
+		crc.Annotations[crcv1alpha1.SnapshotAnnotation] = snapshot.Name
+		if err := r.client.Update(context.TODO(), crc); err != nil {
+			logger.Error(err, "Failed to annotate CrcCluster.")
+			return reconcile.Result{}, err
+		}
+	}
+	snapshot.Status.Phase = crcv1alpha1.CrcClusterSnapshotPhaseQuiescing
+	snapshot.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeReady, false, "Quiescing", fmt.Sprintf("Shutting down CrcCluster %s gracefully", crc.Name))
+	return reconcile.Result{RequeueAfter: time.Second * 10}, nil
+}
+
+// takeVolumeSnapshot snapshots the cluster's persistent volume once
+// the cluster is stopped
+func (r *ReconcileCrcClusterSnapshot) takeVolumeSnapshot(logger logr.Logger, snapshot *crcv1alpha1.CrcClusterSnapshot, crc *crcv1alpha1.CrcCluster) (reconcile.Result, error) {
+	if crc.Status.PowerState != crcv1alpha1.CrcClusterPowerStateStopped {
+		logger.Info("Waiting on the CrcCluster to stop.", "PowerState", crc.Status.PowerState)
+		return reconcile.Result{RequeueAfter: time.Second * 10}, nil
+	}
+
+	volumeSnapshot := &unstructured.Unstructured{}
+	volumeSnapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	volumeSnapshot.SetName(snapshot.Name)
+	volumeSnapshot.SetNamespace(snapshot.Namespace)
+	volumeSnapshot.SetLabels(map[string]string{
+		"crcCluster": crc.Name,
+	})
+	volumeSnapshotSpec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": crcv1alpha1.RootVolumeName(crc.Name),
+		},
+	}
+	if snapshot.Spec.VolumeSnapshotClassName != "" {
+		volumeSnapshotSpec["volumeSnapshotClassName"] = snapshot.Spec.VolumeSnapshotClassName
+	}
+	volumeSnapshot.Object["spec"] = volumeSnapshotSpec
+	if err := controllerutil.SetControllerReference(snapshot, volumeSnapshot, r.scheme); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	logger.Info("Creating VolumeSnapshot.", "VolumeSnapshot.Name", volumeSnapshot.GetName())
+	if err := r.client.Create(context.TODO(), volumeSnapshot); err != nil && !errors.IsAlreadyExists(err) {
+		logger.Error(err, "Failed to create VolumeSnapshot.")
+		r.fail(snapshot, "VolumeSnapshotFailed", fmt.Sprintf("Unable to create VolumeSnapshot: %v", err))
+		return reconcile.Result{}, r.release(logger, snapshot, crc)
+	}
+	snapshot.Status.VolumeSnapshotName = volumeSnapshot.GetName()
+	snapshot.Status.Phase = crcv1alpha1.CrcClusterSnapshotPhaseSnapshotting
+	snapshot.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeReady, false, "Snapshotting", fmt.Sprintf("Taking VolumeSnapshot %s", volumeSnapshot.GetName()))
+	return reconcile.Result{RequeueAfter: time.Second * 5}, nil
+}
+
+// waitForVolumeSnapshot starts the cluster again as soon as its
+// persistent volume has been snapshotted and marks the snapshot Ready
+// once the VolumeSnapshot is ready to use
+func (r *ReconcileCrcClusterSnapshot) waitForVolumeSnapshot(logger logr.Logger, snapshot *crcv1alpha1.CrcClusterSnapshot, crc *crcv1alpha1.CrcCluster) (reconcile.Result, error) {
+	volumeSnapshot := &unstructured.Unstructured{}
+	volumeSnapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: snapshot.Status.VolumeSnapshotName, Namespace: snapshot.Namespace}, volumeSnapshot)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			r.fail(snapshot, "VolumeSnapshotDeleted", fmt.Sprintf("VolumeSnapshot %s was deleted", snapshot.Status.VolumeSnapshotName))
+			return reconcile.Result{}, r.release(logger, snapshot, crc)
+		}
+		logger.Error(err, "Failed to get VolumeSnapshot.")
+		return reconcile.Result{}, err
+	}
+
+	if message, found, _ := unstructured.NestedString(volumeSnapshot.Object, "status", "error", "message"); found && message != "" {
+		r.fail(snapshot, "VolumeSnapshotFailed", message)
+		return reconcile.Result{}, r.release(logger, snapshot, crc)
+	}
+	if creationTime, found, _ := unstructured.NestedString(volumeSnapshot.Object, "status", "creationTime"); found && snapshot.Status.SnapshotTime == nil {
+		snapshotTime := metav1.Now()
+		if parsed, err := time.Parse(time.RFC3339, creationTime); err == nil {
+			snapshotTime = metav1.NewTime(parsed)
+		}
+		snapshot.Status.SnapshotTime = &snapshotTime
+	}
+	if snapshot.Status.SnapshotTime != nil {
+		// The snapshot is cut, so the cluster can start again while
+		// the storage finishes it
+		if err := r.release(logger, snapshot, crc); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	readyToUse, _, _ := unstructured.NestedBool(volumeSnapshot.Object, "status", "readyToUse")
+	if !readyToUse {
+		logger.Info("Waiting on VolumeSnapshot to be ready to use.", "VolumeSnapshot.Name", volumeSnapshot.GetName())
+		return reconcile.Result{RequeueAfter: time.Second * 10}, nil
+	}
+	if restoreSize, found, _ := unstructured.NestedString(volumeSnapshot.Object, "status", "restoreSize"); found {
+		snapshot.Status.RestoreSize = restoreSize
+	}
+	snapshot.Status.Phase = crcv1alpha1.CrcClusterSnapshotPhaseReady
+	snapshot.SetConditionBool(crcv1alpha1.ConditionTypeReady, true)
+	return reconcile.Result{}, nil
+}
+
+// fail marks the snapshot as Failed for the given reason
+func (r *ReconcileCrcClusterSnapshot) fail(snapshot *crcv1alpha1.CrcClusterSnapshot, reason status.ConditionReason, message string) {
+	snapshot.Status.Phase = crcv1alpha1.CrcClusterSnapshotPhaseFailed
+	snapshot.SetConditionBoolWithMessage(crcv1alpha1.ConditionTypeReady, false, reason, message)
+}
+
+// release removes the snapshot's annotation from the CrcCluster so
+// it gets started again
+func (r *ReconcileCrcClusterSnapshot) release(logger logr.Logger, snapshot *crcv1alpha1.CrcClusterSnapshot, crc *crcv1alpha1.CrcCluster) error {
+	if crc.Annotations[crcv1alpha1.SnapshotAnnotation] != snapshot.Name {
+		return nil
+	}
+	logger.Info("Starting CrcCluster again after snapshotting it.", "CrcCluster.Name", crc.Name)
+	delete(crc.Annotations, crcv1alpha1.SnapshotAnnotation)
+	if err := r.client.Update(context.TODO(), crc); err != nil {
+		logger.Error(err, "Failed to remove snapshot annotation from CrcCluster.")
+		return err
+	}
+	return nil
+}
+
+func (r *ReconcileCrcClusterSnapshot) updateSnapshotStatus(logger logr.Logger, snapshot *crcv1alpha1.CrcClusterSnapshot, existingSnapshot *crcv1alpha1.CrcClusterSnapshot) error {
+	if !reflect.DeepEqual(snapshot.Status, existingSnapshot.Status) {
+		if err := r.client.Status().Update(context.TODO(), snapshot); err != nil {
+			logger.Error(err, "Failed to update CrcClusterSnapshot status.")
+			return err
+		}
+	}
+	return nil
+}
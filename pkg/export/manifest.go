@@ -0,0 +1,78 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Keys of the Secret of a CrcClusterExport. The username and
+// password use the same keys CDI expects for the Secret of an HTTP
+// import, so a copy of the Secret works for importing the cluster.
+const (
+	ManifestKey = "manifest.json"
+	UsernameKey = "accessKeyId"
+	PasswordKey = "secretKey"
+)
+
+// Manifest describes an exported cluster, with everything needed to
+// import its disk image as a new cluster
+type Manifest struct {
+	// ClusterName is the name of the exported CrcCluster
+	ClusterName string `json:"clusterName"`
+
+	// Namespace is the namespace of the exported CrcCluster
+	Namespace string `json:"namespace"`
+
+	// ExportTime is when the cluster got exported
+	ExportTime metav1.Time `json:"exportTime"`
+
+	// BundleName is the name of the bundle the cluster was created
+	// from, which has to exist wherever it gets imported
+	BundleName string `json:"bundleName"`
+
+	// BundleImage is the bundle image the cluster was created from,
+	// if it overrode the image of its bundle
+	BundleImage string `json:"bundleImage,omitempty"`
+
+	// DiskSize is the size of the cluster's persistent volume, the
+	// minimum storage size of an imported cluster
+	DiskSize string `json:"diskSize"`
+
+	// Spec is the exported cluster's spec, for reference
+	Spec crcv1alpha1.CrcClusterSpec `json:"spec"`
+
+	// ClusterID is the ID of the exported cluster
+	ClusterID string `json:"clusterID"`
+
+	// KubeAdminPassword is the password to connect to the exported
+	// cluster as an administrator
+	KubeAdminPassword string `json:"kubeAdminPassword"`
+
+	// KubeAdminClientKey is the base64-encoded client key to connect
+	// to the exported cluster as an administrator
+	KubeAdminClientKey string `json:"kubeAdminClientKey"`
+
+	// SSHKey is the base64-encoded SSH key of the exported cluster
+	SSHKey string `json:"sshKey"`
+}
+
+// ManifestFromSecret reads the manifest of an exported cluster from
+// a Secret
+func ManifestFromSecret(secret *corev1.Secret) (*Manifest, error) {
+	data, ok := secret.Data[ManifestKey]
+	if !ok {
+		return nil, fmt.Errorf("Secret %s has no %s", secret.Name, ManifestKey)
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("Invalid %s in Secret %s: %v", ManifestKey, secret.Name, err)
+	}
+	if manifest.BundleName == "" || manifest.SSHKey == "" {
+		return nil, fmt.Errorf("Incomplete %s in Secret %s", ManifestKey, secret.Name)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("export_server")
+
+// Environment variables the export server gets configured with
+const (
+	DiskPathEnv     = "EXPORT_DISK_PATH"
+	ManifestPathEnv = "EXPORT_MANIFEST_PATH"
+	UsernameEnv     = "EXPORT_USERNAME"
+	PasswordEnv     = "EXPORT_PASSWORD"
+)
+
+// ServerPort is the port the export server listens on
+const ServerPort = 8080
+
+// ServerCommand is the argument that runs the operator's binary as
+// an export server instead of the operator
+const ServerCommand = "export-server"
+
+// Paths the export server serves the disk image and manifest at
+const (
+	DiskPath     = "/disk.img"
+	ManifestPath = "/manifest.json"
+	HealthPath   = "/healthz"
+)
+
+// Serve serves an exported cluster's disk image and manifest, behind
+// basic auth, until the process gets killed. The disk image is
+// served as is, either the disk.img of a Filesystem volume or the
+// whole device of a Block volume, with support for range requests so
+// interrupted downloads can be resumed.
+func Serve() error {
+	diskPath := os.Getenv(DiskPathEnv)
+	manifestPath := os.Getenv(ManifestPathEnv)
+	username := os.Getenv(UsernameEnv)
+	password := os.Getenv(PasswordEnv)
+	if diskPath == "" || manifestPath == "" || username == "" || password == "" {
+		return fmt.Errorf("%s, %s, %s, and %s environment variables must be set", DiskPathEnv, ManifestPathEnv, UsernameEnv, PasswordEnv)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(HealthPath, func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle(DiskPath, basicAuth(username, password, serveFile(diskPath, "application/octet-stream")))
+	mux.Handle(ManifestPath, basicAuth(username, password, serveFile(manifestPath, "application/json")))
+
+	log.Info("Serving export.", "Port", ServerPort, "Disk", diskPath)
+	return http.ListenAndServe(fmt.Sprintf(":%d", ServerPort), mux)
+}
+
+func basicAuth(username, password string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		reqUsername, reqPassword, ok := req.BasicAuth()
+		if !ok ||
+			subtle.ConstantTimeCompare([]byte(reqUsername), []byte(username)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(reqPassword), []byte(password)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="crc-export"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+func serveFile(path string, contentType string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Error(err, "Failed to open exported file.", "Path", path)
+			http.Error(w, "Export unavailable", http.StatusInternalServerError)
+			return
+		}
+		defer file.Close()
+		info, err := file.Stat()
+		if err != nil {
+			log.Error(err, "Failed to stat exported file.", "Path", path)
+			http.Error(w, "Export unavailable", http.StatusInternalServerError)
+			return
+		}
+		log.Info("Serving exported file.", "Path", path, "Remote", req.RemoteAddr, "Range", req.Header.Get("Range"))
+		w.Header().Set("Content-Type", contentType)
+		// ServeContent finds the size by seeking, which works for
+		// block devices too
+		http.ServeContent(w, req, "", info.ModTime(), file)
+	})
+}
@@ -0,0 +1,173 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/bbrowning/crc-operator/pkg/bundle"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ViolationError is returned when a CrcCluster violates a
+// CrcClusterPolicy
+type ViolationError struct {
+	Policy string
+	Reason string
+}
+
+func (e *ViolationError) Error() string {
+	return fmt.Sprintf("Violates CrcClusterPolicy %s - %s", e.Policy, e.Reason)
+}
+
+// IsViolation returns true if the given error is a ViolationError
+func IsViolation(err error) bool {
+	_, ok := err.(*ViolationError)
+	return ok
+}
+
+// ForNamespace returns all CrcClusterPolicies that apply to
+// CrcClusters in the given namespace
+func ForNamespace(c client.Client, namespace string) ([]crcv1alpha1.CrcClusterPolicy, error) {
+	policies := []crcv1alpha1.CrcClusterPolicy{}
+
+	policyList := &crcv1alpha1.CrcClusterPolicyList{}
+	if err := c.List(context.TODO(), policyList); err != nil {
+		return policies, err
+	}
+	if len(policyList.Items) == 0 {
+		return policies, nil
+	}
+
+	ns := &corev1.Namespace{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: namespace}, ns); err != nil {
+		return policies, err
+	}
+	for _, policy := range policyList.Items {
+		if policy.Spec.NamespaceSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+			if err != nil {
+				return policies, fmt.Errorf("Invalid namespaceSelector in CrcClusterPolicy %s: %v", policy.Name, err)
+			}
+			if !selector.Matches(labels.Set(ns.Labels)) {
+				continue
+			}
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// Check returns a ViolationError if the given CrcCluster violates any
+// CrcClusterPolicy that applies to its namespace
+func Check(c client.Client, crc *crcv1alpha1.CrcCluster) error {
+	policies, err := ForNamespace(c, crc.Namespace)
+	if err != nil {
+		return err
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+
+	crcBundle, imageOverridden, err := bundle.ForCrcCluster(c, crc)
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		reason, err := violation(policy.Spec, crc, crcBundle, imageOverridden)
+		if err != nil {
+			return fmt.Errorf("Invalid CrcClusterPolicy %s: %v", policy.Name, err)
+		}
+		if reason != "" {
+			return &ViolationError{Policy: policy.Name, Reason: reason}
+		}
+	}
+	return nil
+}
+
+// CheckUpdate is like Check, but only returns a ViolationError if the
+// update changes any of the fields policies care about. This prevents
+// tightening a policy from blocking unrelated changes, like stopping
+// a cluster.
+func CheckUpdate(c client.Client, oldCrc *crcv1alpha1.CrcCluster, crc *crcv1alpha1.CrcCluster) error {
+	if oldCrc.Spec.BundleName == crc.Spec.BundleName &&
+		oldCrc.Spec.BundleImage == crc.Spec.BundleImage &&
+		oldCrc.Spec.CPU == crc.Spec.CPU &&
+		oldCrc.Spec.Memory == crc.Spec.Memory &&
+		oldCrc.Spec.Storage.Persistent == crc.Spec.Storage.Persistent &&
+		reflect.DeepEqual(oldCrc.Spec.Storage.AdditionalDisks, crc.Spec.Storage.AdditionalDisks) {
+		return nil
+	}
+	return Check(c, crc)
+}
+
+// violation returns a description of the first way the given
+// CrcCluster violates a policy, or an empty string if it doesn't.
+func violation(spec crcv1alpha1.CrcClusterPolicySpec, crc *crcv1alpha1.CrcCluster, crcBundle *crcv1alpha1.CrcBundle, imageOverridden bool) (string, error) {
+	if len(spec.AllowedBundles) > 0 {
+		allowed := false
+		for _, name := range spec.AllowedBundles {
+			if name == crcBundle.Name {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Sprintf("bundle %s is not allowed, allowed bundles are %v", crcBundle.Name, spec.AllowedBundles), nil
+		}
+	}
+	if imageOverridden && !spec.AllowBundleImage {
+		return fmt.Sprintf("bundleImage %s does not match any known bundle", crc.Spec.BundleImage), nil
+	}
+	if spec.MaxCPU != nil && crc.Spec.CPU > *spec.MaxCPU {
+		return fmt.Sprintf("cpu %d is more than the maximum of %d", crc.Spec.CPU, *spec.MaxCPU), nil
+	}
+	if spec.MaxMemory != "" && crc.Spec.Memory != "" {
+		maxMemory, err := resource.ParseQuantity(spec.MaxMemory)
+		if err != nil {
+			return "", err
+		}
+		memory, err := resource.ParseQuantity(crc.Spec.Memory)
+		if err != nil {
+			return "", err
+		}
+		if memory.Cmp(maxMemory) > 0 {
+			return fmt.Sprintf("memory %s is more than the maximum of %s", crc.Spec.Memory, spec.MaxMemory), nil
+		}
+	}
+	if spec.AllowPersistentStorage != nil && !*spec.AllowPersistentStorage {
+		if crc.Spec.Storage.Persistent {
+			return "persistent storage is not allowed", nil
+		}
+		for _, disk := range crc.Spec.Storage.AdditionalDisks {
+			if disk.Persistent {
+				return fmt.Sprintf("persistent additional disk %s is not allowed", disk.Name), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// MaxLifetime returns the shortest maximum lifetime of the given
+// policies, or nil if none of them limit the lifetime of clusters
+func MaxLifetime(policies []crcv1alpha1.CrcClusterPolicy) *time.Duration {
+	var maxLifetime *time.Duration
+	for _, policy := range policies {
+		if policy.Spec.MaxLifetime == nil {
+			continue
+		}
+		lifetime := policy.Spec.MaxLifetime.Duration
+		if maxLifetime == nil || lifetime < *maxLifetime {
+			maxLifetime = &lifetime
+		}
+	}
+	return maxLifetime
+}
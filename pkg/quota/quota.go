@@ -0,0 +1,277 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/bbrowning/crc-operator/pkg/bundle"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Usage is the amount of resources used by one or more CrcClusters
+type Usage struct {
+	Clusters int
+	CPU      int
+	Memory   resource.Quantity
+	Storage  resource.Quantity
+}
+
+// Add adds the resources in other to this Usage
+func (u *Usage) Add(other Usage) {
+	u.Clusters += other.Clusters
+	u.CPU += other.CPU
+	u.Memory.Add(other.Memory)
+	u.Storage.Add(other.Storage)
+}
+
+// ToStatus converts this Usage into its CrcClusterQuota status
+// representation
+func (u Usage) ToStatus() crcv1alpha1.CrcClusterQuotaUsage {
+	usage := crcv1alpha1.CrcClusterQuotaUsage{
+		Clusters: u.Clusters,
+		CPU:      u.CPU,
+	}
+	if !u.Memory.IsZero() {
+		usage.Memory = u.Memory.String()
+	}
+	if !u.Storage.IsZero() {
+		usage.Storage = u.Storage.String()
+	}
+	return usage
+}
+
+// exceeds returns a description of the first resource in this Usage
+// above the limits in the given quota spec, or an empty string if
+// no limits are exceeded.
+func (u Usage) exceeds(spec crcv1alpha1.CrcClusterQuotaSpec) (string, error) {
+	if spec.Clusters != nil && u.Clusters > *spec.Clusters {
+		return fmt.Sprintf("clusters: requested %d, limited to %d", u.Clusters, *spec.Clusters), nil
+	}
+	if spec.CPU != nil && u.CPU > *spec.CPU {
+		return fmt.Sprintf("cpu: requested %d, limited to %d", u.CPU, *spec.CPU), nil
+	}
+	if spec.Memory != "" {
+		limit, err := resource.ParseQuantity(spec.Memory)
+		if err != nil {
+			return "", err
+		}
+		if u.Memory.Cmp(limit) > 0 {
+			return fmt.Sprintf("memory: requested %s, limited to %s", u.Memory.String(), limit.String()), nil
+		}
+	}
+	if spec.Storage != "" {
+		limit, err := resource.ParseQuantity(spec.Storage)
+		if err != nil {
+			return "", err
+		}
+		if u.Storage.Cmp(limit) > 0 {
+			return fmt.Sprintf("storage: requested %s, limited to %s", u.Storage.String(), limit.String()), nil
+		}
+	}
+	return "", nil
+}
+
+// increases returns true if any resource in this Usage is larger
+// than the same resource in other
+func (u Usage) increases(other Usage) bool {
+	return u.Clusters > other.Clusters ||
+		u.CPU > other.CPU ||
+		u.Memory.Cmp(other.Memory) > 0 ||
+		u.Storage.Cmp(other.Storage) > 0
+}
+
+// ExceededError is returned when a CrcCluster would exceed a
+// CrcClusterQuota
+type ExceededError struct {
+	Quota  string
+	Reason string
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("Exceeded CrcClusterQuota %s - %s", e.Quota, e.Reason)
+}
+
+// IsExceeded returns true if the given error is an ExceededError
+func IsExceeded(err error) bool {
+	_, ok := err.(*ExceededError)
+	return ok
+}
+
+// Requester returns the name of the user that requested the given
+// CrcCluster or CrcClusterClaim, or an empty string if not known
+func Requester(obj metav1.Object) string {
+	return obj.GetAnnotations()[crcv1alpha1.RequesterAnnotation]
+}
+
+// ClusterUsage returns the resources used by a single CrcCluster.
+// CPU and memory are only used by running clusters while the
+// cluster itself and any persistent storage are used whether running
+// or stopped. Clusters that have not been admitted because they
+// exceeded a quota use no resources.
+func ClusterUsage(c client.Client, crc *crcv1alpha1.CrcCluster) (Usage, error) {
+	usage := Usage{}
+	if crc.Status.Conditions.IsTrueFor(crcv1alpha1.ConditionTypeQuotaExceeded) {
+		return usage, nil
+	}
+	usage.Clusters = 1
+	if !crc.Spec.Stopped {
+		usage.CPU = crc.Spec.CPU
+		if crc.Spec.Memory != "" {
+			memory, err := resource.ParseQuantity(crc.Spec.Memory)
+			if err != nil {
+				return usage, err
+			}
+			usage.Memory = memory
+		}
+	}
+	if crc.Spec.Storage.Persistent {
+		storage, err := persistentStorageSize(c, crc)
+		if err != nil {
+			return usage, err
+		}
+		usage.Storage = storage
+	}
+	for _, disk := range crc.Spec.Storage.AdditionalDisks {
+		if !disk.Persistent {
+			continue
+		}
+		storage, err := resource.ParseQuantity(disk.Size)
+		if err != nil {
+			return usage, err
+		}
+		usage.Storage.Add(storage)
+	}
+	return usage, nil
+}
+
+// persistentStorageSize returns the size of the persistent disk for
+// a CrcCluster, falling back to the disk size of its bundle when no
+// size was requested
+func persistentStorageSize(c client.Client, crc *crcv1alpha1.CrcCluster) (resource.Quantity, error) {
+	if crc.Spec.Storage.Size != "" {
+		return resource.ParseQuantity(crc.Spec.Storage.Size)
+	}
+	crcBundle, _, err := bundle.ForCrcCluster(c, crc)
+	if err != nil {
+		// A cluster without a valid bundle will never get created,
+		// so it doesn't use any storage
+		return resource.Quantity{}, nil
+	}
+	return resource.ParseQuantity(crcBundle.Spec.DiskSize)
+}
+
+// NamespaceUsage returns the resources used by all CrcClusters in a
+// namespace, both in total and per requesting user. The CrcCluster
+// named exclude, if any, is left out.
+func NamespaceUsage(c client.Client, namespace string, exclude string) (Usage, map[string]Usage, error) {
+	total := Usage{}
+	users := map[string]Usage{}
+
+	crcList := &crcv1alpha1.CrcClusterList{}
+	err := c.List(context.TODO(), crcList, &client.ListOptions{Namespace: namespace})
+	if err != nil {
+		return total, users, err
+	}
+	for i := range crcList.Items {
+		crc := &crcList.Items[i]
+		if crc.Name == exclude || crc.DeletionTimestamp != nil {
+			continue
+		}
+		usage, err := ClusterUsage(c, crc)
+		if err != nil {
+			return total, users, err
+		}
+		total.Add(usage)
+		if requester := Requester(crc); requester != "" {
+			userUsage := users[requester]
+			userUsage.Add(usage)
+			users[requester] = userUsage
+		}
+	}
+	return total, users, nil
+}
+
+// Check returns an ExceededError if admitting the given CrcCluster
+// would exceed any CrcClusterQuota in its namespace
+func Check(c client.Client, crc *crcv1alpha1.CrcCluster) error {
+	quotaList := &crcv1alpha1.CrcClusterQuotaList{}
+	err := c.List(context.TODO(), quotaList, &client.ListOptions{Namespace: crc.Namespace})
+	if err != nil {
+		return err
+	}
+	if len(quotaList.Items) == 0 {
+		return nil
+	}
+
+	// Evaluate the cluster as if it had been admitted
+	admittedCrc := crc.DeepCopy()
+	admittedCrc.SetConditionBool(crcv1alpha1.ConditionTypeQuotaExceeded, false)
+	crcUsage, err := ClusterUsage(c, admittedCrc)
+	if err != nil {
+		return err
+	}
+
+	total, users, err := NamespaceUsage(c, crc.Namespace, crc.Name)
+	if err != nil {
+		return err
+	}
+	total.Add(crcUsage)
+	requester := Requester(crc)
+	userUsage := users[requester]
+	userUsage.Add(crcUsage)
+
+	for _, quota := range quotaList.Items {
+		usage := total
+		if quota.Spec.Scope == crcv1alpha1.CrcClusterQuotaScopeUser {
+			if requester == "" {
+				// Without a known requester there is no user to
+				// hold to this quota
+				continue
+			}
+			usage = userUsage
+		}
+		reason, err := usage.exceeds(quota.Spec)
+		if err != nil {
+			return fmt.Errorf("Invalid CrcClusterQuota %s: %v", quota.Name, err)
+		}
+		if reason != "" {
+			return &ExceededError{Quota: quota.Name, Reason: reason}
+		}
+	}
+	return nil
+}
+
+// CheckUpdate is like Check, but only returns an ExceededError if
+// the updated CrcCluster uses more of some resource than the old
+// one. This prevents lowering a quota from blocking unrelated
+// changes to clusters already over it.
+func CheckUpdate(c client.Client, oldCrc *crcv1alpha1.CrcCluster, crc *crcv1alpha1.CrcCluster) error {
+	oldUsage, err := ClusterUsage(c, oldCrc)
+	if err != nil {
+		return err
+	}
+	admittedCrc := crc.DeepCopy()
+	admittedCrc.SetConditionBool(crcv1alpha1.ConditionTypeQuotaExceeded, false)
+	newUsage, err := ClusterUsage(c, admittedCrc)
+	if err != nil {
+		return err
+	}
+	if !newUsage.increases(oldUsage) && Requester(oldCrc) == Requester(crc) {
+		return nil
+	}
+	return Check(c, crc)
+}
+
+// UserNames returns the sorted names of all users in a usage map
+func UserNames(users map[string]Usage) []string {
+	names := make([]string, 0, len(users))
+	for name := range users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
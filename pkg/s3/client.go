@@ -0,0 +1,270 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// unsignedPayload lets requests skip hashing their bodies, so uploads
+// can be streamed without reading them twice
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// defaultHTTPClient sends requests of clients without their own
+// HTTPClient. Its timeout covers reading the whole response, so it
+// leaves room for downloading large backups.
+var defaultHTTPClient = &http.Client{Timeout: 10 * time.Minute}
+
+// Client is a minimal client for S3-compatible storage, like AWS S3
+// or MinIO, using path-style requests signed with AWS Signature
+// Version 4
+type Client struct {
+	// Endpoint is the URL of the storage, like
+	// http://minio.minio.svc:9000
+	Endpoint string
+
+	// Region is the region requests get signed for
+	Region string
+
+	// Bucket is the bucket all objects get stored in
+	Bucket string
+
+	// AccessKeyID and SecretAccessKey are the credentials requests
+	// get signed with
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// HTTPClient sends the requests. If not set, a client that times
+	// out after 10 minutes gets used.
+	HTTPClient *http.Client
+}
+
+// Object is an object in the bucket
+type Object struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+// Error is an error response from the storage
+type Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("S3 request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("S3 request failed with status %d: %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// IsNotFound returns true if err is an error response for an object
+// or bucket that doesn't exist
+func IsNotFound(err error) bool {
+	s3Err, ok := err.(*Error)
+	return ok && s3Err.StatusCode == http.StatusNotFound
+}
+
+// PutObject uploads size bytes of body as the object at key
+func (c *Client) PutObject(key string, body io.Reader, size int64) error {
+	resp, err := c.do(http.MethodPut, key, nil, body, size)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// GetObject downloads the object at key. The caller has to close
+// the returned body.
+func (c *Client) GetObject(key string) (io.ReadCloser, error) {
+	resp, err := c.do(http.MethodGet, key, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// DeleteObject deletes the object at key
+func (c *Client) DeleteObject(key string) error {
+	resp, err := c.do(http.MethodDelete, key, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// ListObjects lists all objects whose keys start with prefix
+func (c *Client) ListObjects(prefix string) ([]Object, error) {
+	objects := []Object{}
+	continuationToken := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+		resp, err := c.do(http.MethodGet, "", query, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		result := struct {
+			Contents              []Object `xml:"Contents"`
+			IsTruncated           bool     `xml:"IsTruncated"`
+			NextContinuationToken string   `xml:"NextContinuationToken"`
+		}{}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decode S3 object list: %v", err)
+		}
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+// do sends a signed request for the object at key, or the bucket
+// itself if key is empty, and turns error responses into an *Error
+func (c *Client) do(method string, key string, query url.Values, body io.Reader, size int64) (*http.Response, error) {
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid S3 endpoint %s: %v", c.Endpoint, err)
+	}
+	path := "/" + c.Bucket
+	if key != "" {
+		path = path + "/" + key
+	}
+	reqURL := &url.URL{
+		Scheme:   endpoint.Scheme,
+		Host:     endpoint.Host,
+		Path:     path,
+		RawPath:  uriEncode(path, false),
+		RawQuery: canonicalQuery(query),
+	}
+	req, err := http.NewRequest(method, reqURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	c.sign(req, unsignedPayload, time.Now().UTC())
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	s3Err := &Error{StatusCode: resp.StatusCode}
+	if data, err := ioutil.ReadAll(io.LimitReader(resp.Body, 64*1024)); err == nil {
+		// Error responses to HEAD requests and some proxies have no
+		// XML body, which just leaves the code and message empty
+		_ = xml.Unmarshal(data, s3Err)
+	}
+	return nil, s3Err
+}
+
+// sign adds the AWS Signature Version 4 headers to req for a body
+// with the given hex SHA256 hash, or unsignedPayload
+func (c *Client) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", req.URL.Host, payloadHash, amzDate)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, c.Region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+c.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, c.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", c.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes query sorted by key the way Signature
+// Version 4 expects, which also works as the request's query
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	params := []string{}
+	for _, key := range keys {
+		for _, value := range query[key] {
+			params = append(params, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// uriEncode percent-encodes everything but unreserved characters,
+// and slashes unless encodeSlash is set
+func uriEncode(s string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for _, b := range []byte(s) {
+		switch {
+		case b >= 'A' && b <= 'Z', b >= 'a' && b <= 'z', b >= '0' && b <= '9',
+			b == '-', b == '_', b == '.', b == '~':
+			encoded.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			encoded.WriteByte(b)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return encoded.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,112 @@
+package size
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// clusterSizes is a JSON-encoded map of size names to Presets that
+// replaces the built-in presets
+var clusterSizes = os.Getenv("CLUSTER_SIZES")
+var defaultClusterSize = os.Getenv("DEFAULT_CLUSTER_SIZE")
+
+// Preset is the CPU and memory of a named cluster size
+type Preset struct {
+	CPU    int    `json:"cpu"`
+	Memory string `json:"memory"`
+}
+
+// builtinPresets are used unless CLUSTER_SIZES is set. The medium
+// size matches the defaults from before sizes existed, and large is
+// enough to run cluster monitoring.
+var builtinPresets = map[string]Preset{
+	"small":  {CPU: 4, Memory: "12Gi"},
+	"medium": {CPU: 4, Memory: "16Gi"},
+	"large":  {CPU: 6, Memory: "20Gi"},
+}
+
+const builtinDefaultSize = "medium"
+
+// UnknownSizeError is returned when a CrcCluster asks for a size
+// that doesn't exist
+type UnknownSizeError struct {
+	Size  string
+	Known []string
+}
+
+func (e *UnknownSizeError) Error() string {
+	return fmt.Sprintf("Unknown size %s, known sizes are %v", e.Size, e.Known)
+}
+
+// IsUnknownSize returns true if the given error is an
+// UnknownSizeError
+func IsUnknownSize(err error) bool {
+	_, ok := err.(*UnknownSizeError)
+	return ok
+}
+
+// Presets returns all known size presets
+func Presets() (map[string]Preset, error) {
+	if clusterSizes == "" {
+		return builtinPresets, nil
+	}
+	presets := map[string]Preset{}
+	if err := json.Unmarshal([]byte(clusterSizes), &presets); err != nil {
+		return presets, fmt.Errorf("Invalid CLUSTER_SIZES environment variable: %v", err)
+	}
+	for name, preset := range presets {
+		if preset.CPU < 1 {
+			return presets, fmt.Errorf("Invalid CLUSTER_SIZES environment variable: size %s needs at least 1 cpu", name)
+		}
+		if _, err := resource.ParseQuantity(preset.Memory); err != nil {
+			return presets, fmt.Errorf("Invalid CLUSTER_SIZES environment variable: size %s has invalid memory: %v", name, err)
+		}
+	}
+	return presets, nil
+}
+
+// Apply fills in the CPU and Memory of the given CrcClusterSpec from
+// its size preset, leaving any explicitly set values alone. It
+// returns true if anything changed.
+func Apply(spec *crcv1alpha1.CrcClusterSpec) (bool, error) {
+	if spec.Size == "" && spec.CPU != 0 && spec.Memory != "" {
+		return false, nil
+	}
+	name := spec.Size
+	if name == "" {
+		name = defaultClusterSize
+	}
+	if name == "" {
+		name = builtinDefaultSize
+	}
+
+	presets, err := Presets()
+	if err != nil {
+		return false, err
+	}
+	preset, found := presets[name]
+	if !found {
+		known := []string{}
+		for knownName := range presets {
+			known = append(known, knownName)
+		}
+		sort.Strings(known)
+		return false, &UnknownSizeError{Size: name, Known: known}
+	}
+
+	changed := false
+	if spec.CPU == 0 {
+		spec.CPU = preset.CPU
+		changed = true
+	}
+	if spec.Memory == "" {
+		spec.Memory = preset.Memory
+		changed = true
+	}
+	return changed, nil
+}
@@ -0,0 +1,246 @@
+package crccluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/bbrowning/crc-operator/pkg/policy"
+	"github.com/bbrowning/crc-operator/pkg/quota"
+	"github.com/bbrowning/crc-operator/pkg/size"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var log = logf.Log.WithName("webhook_crccluster")
+
+var operatorNs = os.Getenv("POD_NAMESPACE")
+
+const (
+	mutatePath   string = "/mutate-crccluster"
+	validatePath string = "/validate-crccluster"
+)
+
+// Add registers the CrcCluster mutating and validating webhooks with
+// the Manager's webhook server
+func Add(mgr manager.Manager) error {
+	server := mgr.GetWebhookServer()
+	server.Register(mutatePath, &webhook.Admission{Handler: &crcClusterMutator{}})
+	server.Register(validatePath, &webhook.Admission{Handler: &crcClusterValidator{client: mgr.GetClient()}})
+	return nil
+}
+
+// isOperator returns true if the admission request was made by the
+// CRC Operator itself
+func isOperator(req admission.Request) bool {
+	return operatorNs != "" && strings.HasPrefix(req.UserInfo.Username, fmt.Sprintf("system:serviceaccount:%s:", operatorNs))
+}
+
+// crcClusterMutator records the user requesting each new CrcCluster
+// and fills in the CPU and memory of its size
+type crcClusterMutator struct {
+	decoder *admission.Decoder
+}
+
+// Handle implements admission.Handler
+func (m *crcClusterMutator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1beta1.Create && req.Operation != admissionv1beta1.Update {
+		return admission.Allowed("")
+	}
+	crc := &crcv1alpha1.CrcCluster{}
+	if err := m.decoder.Decode(req, crc); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// A new size replaces the CPU and memory of the old one, unless
+	// they get changed along with it
+	if req.Operation == admissionv1beta1.Update {
+		oldCrc := &crcv1alpha1.CrcCluster{}
+		if err := m.decoder.DecodeRaw(req.OldObject, oldCrc); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if crc.Spec.Size != oldCrc.Spec.Size && crc.Spec.CPU == oldCrc.Spec.CPU && crc.Spec.Memory == oldCrc.Spec.Memory {
+			crc.Spec.CPU = 0
+			crc.Spec.Memory = ""
+		}
+	}
+
+	changed, err := size.Apply(&crc.Spec)
+	if err != nil {
+		if size.IsUnknownSize(err) {
+			return admission.Denied(err.Error())
+		}
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	// The operator creates CrcClusters on behalf of other users and
+	// records the requester itself
+	if req.Operation == admissionv1beta1.Create && !(isOperator(req) && quota.Requester(crc) != "") {
+		if crc.Annotations == nil {
+			crc.Annotations = map[string]string{}
+		}
+		crc.Annotations[crcv1alpha1.RequesterAnnotation] = req.UserInfo.Username
+		changed = true
+	}
+
+	if !changed {
+		return admission.Allowed("")
+	}
+	marshaledCrc, err := json.Marshal(crc)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaledCrc)
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (m *crcClusterMutator) InjectDecoder(d *admission.Decoder) error {
+	m.decoder = d
+	return nil
+}
+
+// crcClusterValidator rejects CrcClusters that violate a
+// CrcClusterPolicy or would exceed a CrcClusterQuota
+type crcClusterValidator struct {
+	client  client.Client
+	decoder *admission.Decoder
+}
+
+// Handle implements admission.Handler
+func (v *crcClusterValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1beta1.Create && req.Operation != admissionv1beta1.Update {
+		return admission.Allowed("")
+	}
+	crc := &crcv1alpha1.CrcCluster{}
+	if err := v.decoder.Decode(req, crc); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	oldCrc := &crcv1alpha1.CrcCluster{}
+	if req.Operation == admissionv1beta1.Update {
+		if err := v.decoder.DecodeRaw(req.OldObject, oldCrc); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+	if !reflect.DeepEqual(crc.Spec.Storage.AdditionalDisks, oldCrc.Spec.Storage.AdditionalDisks) {
+		if err := validateAdditionalDisks(crc); err != nil {
+			return admission.Denied(err.Error())
+		}
+	}
+	if sourceNamespace := crossNamespaceSource(crc); sourceNamespace != "" && sourceNamespace != crossNamespaceSource(oldCrc) {
+		allowed, err := v.canCloneFrom(ctx, req, sourceNamespace)
+		if err != nil {
+			log.Error(err, "Failed to check access to the source persistent volume claim.", "CrcCluster.Namespace", crc.Namespace, "CrcCluster.Name", crc.Name)
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		if !allowed {
+			return admission.Denied(fmt.Sprintf("%s can't clone persistent volume claims in namespace %s", req.UserInfo.Username, sourceNamespace))
+		}
+	}
+
+	var err error
+	if req.Operation == admissionv1beta1.Create {
+		err = policy.Check(v.client, crc)
+		if err == nil {
+			err = quota.Check(v.client, crc)
+		}
+	} else {
+		if quota.Requester(oldCrc) != quota.Requester(crc) && !isOperator(req) {
+			return admission.Denied(fmt.Sprintf("The %s annotation can't be changed", crcv1alpha1.RequesterAnnotation))
+		}
+		err = policy.CheckUpdate(v.client, oldCrc, crc)
+		if err == nil {
+			err = quota.CheckUpdate(v.client, oldCrc, crc)
+		}
+	}
+	if err != nil {
+		if policy.IsViolation(err) || quota.IsExceeded(err) {
+			return admission.Denied(err.Error())
+		}
+		log.Error(err, "Failed to check CrcClusterPolicies and CrcClusterQuotas.", "CrcCluster.Namespace", crc.Namespace, "CrcCluster.Name", crc.Name)
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.Allowed("")
+}
+
+// validateAdditionalDisks rejects additional disks whose names are
+// taken by another disk, or by the scratch disk and DataVolume the
+// operator gives the cluster itself
+func validateAdditionalDisks(crc *crcv1alpha1.CrcCluster) error {
+	names := map[string]bool{}
+	for _, additionalDisk := range crc.Spec.Storage.AdditionalDisks {
+		switch {
+		case additionalDisk.Name == "scratch" || additionalDisk.Name == "datavolume":
+			return fmt.Errorf("Additional disk name %s is reserved", additionalDisk.Name)
+		case names[additionalDisk.Name]:
+			return fmt.Errorf("Additional disk name %s is used more than once", additionalDisk.Name)
+		}
+		names[additionalDisk.Name] = true
+	}
+	return nil
+}
+
+// crossNamespaceSource returns the namespace of the persistent volume
+// claim a cluster gets cloned from, if it's not the cluster's own
+func crossNamespaceSource(crc *crcv1alpha1.CrcCluster) string {
+	if crc.Spec.Source == nil || crc.Spec.Source.PersistentVolumeClaim == nil {
+		return ""
+	}
+	namespace := crc.Spec.Source.PersistentVolumeClaim.Namespace
+	if namespace == crc.Namespace {
+		return ""
+	}
+	return namespace
+}
+
+// canCloneFrom returns true if the requesting user could clone a
+// persistent volume claim in namespace with CDI themselves. The
+// operator clones it with its own cluster-wide permissions, so
+// without this anyone could clone any other tenant's disks. The
+// operator only creates clusters from a CrcClusterPool's template,
+// which anyone able to create a pool could have written, so it
+// never gets to clone from another namespace.
+func (v *crcClusterValidator) canCloneFrom(ctx context.Context, req admission.Request, namespace string) (bool, error) {
+	if isOperator(req) {
+		return false, nil
+	}
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, value := range req.UserInfo.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   req.UserInfo.Username,
+			Groups: req.UserInfo.Groups,
+			UID:    req.UserInfo.UID,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   namespace,
+				Verb:        "create",
+				Group:       "cdi.kubevirt.io",
+				Resource:    "datavolumes",
+				Subresource: "source",
+			},
+		},
+	}
+	if err := v.client.Create(ctx, review); err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (v *crcClusterValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
@@ -0,0 +1,96 @@
+package crcclusterclaim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	crcv1alpha1 "github.com/bbrowning/crc-operator/pkg/apis/crc/v1alpha1"
+	"github.com/bbrowning/crc-operator/pkg/quota"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	mutatePath   string = "/mutate-crcclusterclaim"
+	validatePath string = "/validate-crcclusterclaim"
+)
+
+// Add registers the CrcClusterClaim mutating and validating webhooks
+// with the Manager's webhook server
+func Add(mgr manager.Manager) error {
+	server := mgr.GetWebhookServer()
+	server.Register(mutatePath, &webhook.Admission{Handler: &crcClusterClaimMutator{}})
+	server.Register(validatePath, &webhook.Admission{Handler: &crcClusterClaimValidator{}})
+	return nil
+}
+
+// crcClusterClaimMutator records the user requesting each new
+// CrcClusterClaim, who becomes the requester of the claimed cluster
+type crcClusterClaimMutator struct {
+	decoder *admission.Decoder
+}
+
+// Handle implements admission.Handler
+func (m *crcClusterClaimMutator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1beta1.Create {
+		return admission.Allowed("")
+	}
+	claim := &crcv1alpha1.CrcClusterClaim{}
+	if err := m.decoder.Decode(req, claim); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if claim.Annotations == nil {
+		claim.Annotations = map[string]string{}
+	}
+	claim.Annotations[crcv1alpha1.RequesterAnnotation] = req.UserInfo.Username
+	marshaledClaim, err := json.Marshal(claim)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaledClaim)
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (m *crcClusterClaimMutator) InjectDecoder(d *admission.Decoder) error {
+	m.decoder = d
+	return nil
+}
+
+// crcClusterClaimValidator prevents changing who requested a
+// CrcClusterClaim or which pool it claims from
+type crcClusterClaimValidator struct {
+	decoder *admission.Decoder
+}
+
+// Handle implements admission.Handler
+func (v *crcClusterClaimValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1beta1.Update {
+		return admission.Allowed("")
+	}
+	claim := &crcv1alpha1.CrcClusterClaim{}
+	if err := v.decoder.Decode(req, claim); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	oldClaim := &crcv1alpha1.CrcClusterClaim{}
+	if err := v.decoder.DecodeRaw(req.OldObject, oldClaim); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if quota.Requester(oldClaim) != quota.Requester(claim) {
+		return admission.Denied(fmt.Sprintf("The %s annotation can't be changed", crcv1alpha1.RequesterAnnotation))
+	}
+	if oldClaim.Spec.PoolName != claim.Spec.PoolName {
+		return admission.Denied("The poolName of a CrcClusterClaim can't be changed")
+	}
+	return admission.Allowed("")
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (v *crcClusterClaimValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}